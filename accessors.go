@@ -1,7 +1,10 @@
 package validator
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -87,30 +90,68 @@ func getUUIDValue(v any) (uuid.UUID, error) {
 }
 
 // getIntValue converts the given value to an int if possible. If it
-// is already an int, return it as is. If the value is a string or float,
-// convert it to an int. If the value is not a valid integer, return an error.
+// is already an int, return it as is. If the value is a string, float, or
+// JSON number, convert it to an int. A value with a fractional part, or a
+// value outside the range of an int, is not a valid integer and returns
+// an error.
 func getIntValue(v any) (int, error) {
+	n, err := getIntegerValue(v)
+	if err != nil || !n.IsInt64() || n.Int64() < math.MinInt || n.Int64() > math.MaxInt {
+		return 0, ErrInvalidData.Value(v)
+	}
+
+	return int(n.Int64()), nil
+}
+
+// getIntegerValue converts the given value to an exact integer of any size.
+// A JSON number can be written with a fraction or an exponent, such as 3.0 or
+// 1e3, as long as the value is a whole number. A string must contain decimal
+// digits. If the value is not a whole number, return an error.
+func getIntegerValue(v any) (*big.Int, error) {
 	switch value := v.(type) {
 	case int:
-		return value, nil
+		return big.NewInt(int64(value)), nil
+
+	case int64:
+		return big.NewInt(value), nil
+
+	case uint64:
+		return new(big.Int).SetUint64(value), nil
+
 	case float64:
-		return int(value), nil
+		if math.IsInf(value, 0) || value != math.Trunc(value) {
+			return nil, ErrInvalidData.Value(value)
+		}
+
+		n, _ := big.NewFloat(value).Int(nil)
+
+		return n, nil
+
+	case json.Number:
+		r, ok := parseNumber(string(value))
+		if !ok || !r.IsInt() {
+			return nil, ErrInvalidData.Value(value)
+		}
+
+		return new(big.Int).Set(r.Num()), nil
+
 	case string:
-		n, err := strconv.ParseInt(value, 10, 32)
-		if err != nil {
-			return 0, ErrInvalidData.Value(value)
+		n, ok := new(big.Int).SetString(value, 10)
+		if !ok {
+			return nil, ErrInvalidData.Value(value)
 		}
 
-		return int(n), nil
+		return n, nil
 
 	default:
-		return 0, ErrInvalidData.Value(value)
+		return nil, ErrInvalidData.Value(value)
 	}
 }
 
 // getFloatValue converts the given value to a float64 if possible. If it
-// is already a float64, return it as is. If the value is a string or int,
-// convert it to a float64. If the value is not a valid float, return an error.
+// is already a float64, return it as is. If the value is a string, int, or
+// JSON number, convert it to a float64. If the value is not a valid float,
+// or is too large for a float64, return an error.
 func getFloatValue(v any) (float64, error) {
 	switch value := v.(type) {
 	case float64:
@@ -118,6 +159,14 @@ func getFloatValue(v any) (float64, error) {
 	case int:
 		return float64(value), nil
 
+	case json.Number:
+		n, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return 0, ErrInvalidData.Value(value)
+		}
+
+		return n, nil
+
 	case string:
 		n, err := strconv.ParseFloat(value, 64)
 		if err != nil {
@@ -131,6 +180,66 @@ func getFloatValue(v any) (float64, error) {
 	}
 }
 
+// getDecimalValue converts the given value to an exact decimal number of any
+// size and precision. A float64 is converted using the shortest decimal text
+// that represents it, so a limit such as 0.01 is exactly one hundredth rather
+// than the nearest binary fraction. If the value is not a valid number, return
+// an error.
+func getDecimalValue(v any) (*big.Rat, error) {
+	switch value := v.(type) {
+	case int, int64, uint64:
+		n, _ := getIntegerValue(value)
+
+		return new(big.Rat).SetInt(n), nil
+
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, ErrInvalidData.Value(value)
+		}
+
+		r, _ := parseNumber(strconv.FormatFloat(value, 'g', -1, 64))
+
+		return r, nil
+
+	case json.Number:
+		r, ok := parseNumber(string(value))
+		if !ok {
+			return nil, ErrInvalidData.Value(value)
+		}
+
+		return r, nil
+
+	case string:
+		r, ok := parseNumber(value)
+		if !ok {
+			return nil, ErrInvalidData.Value(value)
+		}
+
+		return r, nil
+
+	default:
+		return nil, ErrInvalidData.Value(value)
+	}
+}
+
+// parseNumber parses the text of a decimal number, with an optional fraction
+// and exponent, as an exact value. The size of the exponent is limited, so a
+// short value such as 1e999999999 cannot use a large amount of memory.
+func parseNumber(text string) (*big.Rat, bool) {
+	if strings.ContainsAny(text, "/_xXoObBpP") {
+		return nil, false
+	}
+
+	if n := strings.IndexAny(text, "eE"); n >= 0 {
+		exponent, err := strconv.Atoi(text[n+1:])
+		if err != nil || exponent > maxExponent || exponent < -maxExponent {
+			return nil, false
+		}
+	}
+
+	return new(big.Rat).SetString(text)
+}
+
 // getStringValue converts the given value to a string if possible. If it
 // is a time, UUID, or duration, or float value, convert it to a string
 // since these are all types that can be encoded as a string in JSON.
@@ -143,6 +252,14 @@ func getStringValue(v any) (string, error) {
 	case float64:
 		return fmt.Sprintf("%f", value), nil
 
+	case json.Number:
+		f, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			return "", ErrInvalidData.Value(value)
+		}
+
+		return fmt.Sprintf("%f", f), nil
+
 	case time.Time:
 		return value.Format(time.RFC3339), nil
 
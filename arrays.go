@@ -0,0 +1,325 @@
+package validator
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// SetUnique sets whether the values of an array must all be different. Values
+// that are objects or arrays are compared by their contents. If the item is not
+// an array, no change is made to the item.
+func (i *Item) SetUnique(b bool) *Item {
+	if i == nil || i.ItemType != TypeArray {
+		return i
+	}
+
+	i.Unique = b
+
+	return i
+}
+
+// SetUniqueBy sets the name of a field of the objects in an array that must have
+// a different value in each object. If the item is not an array, no change is
+// made to the item.
+func (i *Item) SetUniqueBy(field string) *Item {
+	if i == nil || i.ItemType != TypeArray {
+		return i
+	}
+
+	i.UniqueBy = field
+
+	return i
+}
+
+// SetContains sets the validator that at least one of the values of an array
+// must match. The number of matching values can be limited using the
+// SetMinContains() and SetMaxContains() functions. If the item is not an array,
+// no change is made to the item.
+func (i *Item) SetContains(v *Item) *Item {
+	if i == nil || i.ItemType != TypeArray {
+		return i
+	}
+
+	i.Contains = v
+
+	return i
+}
+
+// SetMinContains sets the minimum number of values of an array that must match
+// the contains validator. If the item is not an array, no change is made to the
+// item.
+func (i *Item) SetMinContains(n int) *Item {
+	if i == nil || i.ItemType != TypeArray {
+		return i
+	}
+
+	i.MinContains = n
+	i.HasMinContains = true
+
+	return i
+}
+
+// SetMaxContains sets the maximum number of values of an array that can match
+// the contains validator. If the item is not an array, no change is made to the
+// item.
+func (i *Item) SetMaxContains(n int) *Item {
+	if i == nil || i.ItemType != TypeArray {
+		return i
+	}
+
+	i.MaxContains = n
+	i.HasMaxContains = true
+
+	return i
+}
+
+// validateElements checks the rules for the values of an array as a whole, once
+// each value is known to be valid. A duplicate value is reported using its index
+// in the array, and the contains rules report the number of matching values.
+func (i *Item) validateElements(array []any, depth int) error {
+	if i.Unique || i.UniqueBy != "" {
+		seen := map[string]bool{}
+
+		for n, element := range array {
+			key, ok := i.uniqueKey(element)
+			if !ok {
+				continue
+			}
+
+			if seen[key] {
+				return ErrDuplicateValue.Context(i.Name).Value(n)
+			}
+
+			seen[key] = true
+		}
+	}
+
+	if i.Contains == nil {
+		return nil
+	}
+
+	count := 0
+
+	for _, element := range array {
+		if i.Contains.validateValue(element, depth+1) == nil {
+			count++
+		}
+	}
+
+	if lo := i.minContains(); count < lo {
+		return ErrContainsOutOfRange.Context(i.Name).Value(count).Expected(lo)
+	}
+
+	if i.HasMaxContains && count > i.MaxContains {
+		return ErrContainsOutOfRange.Context(i.Name).Value(count).Expected(i.MaxContains)
+	}
+
+	return nil
+}
+
+// minContains returns the minimum number of values that must match the contains
+// validator, which is one unless a minimum is set.
+func (i *Item) minContains() int {
+	if i.HasMinContains {
+		return i.MinContains
+	}
+
+	return 1
+}
+
+// uniqueKey returns the text used to compare a value of an array with the other
+// values, which is the JSON text of the value, or of the unique_by field of an
+// object. The field is found using the field matching rules of the structure of
+// the array values, if there is one. If the object does not have the field, the
+// value is not compared.
+func (i *Item) uniqueKey(element any) (string, bool) {
+	if i.UniqueBy != "" {
+		m, ok := element.(map[string]any)
+		if !ok {
+			return "", false
+		}
+
+		key := i.UniqueBy
+
+		if s := i.BaseType.structure(); s != nil {
+			if keys, err := s.matchFields(m); err == nil {
+				for field, name := range keys {
+					if field.Name == i.UniqueBy {
+						key = name
+					}
+				}
+			}
+		}
+
+		value, found := m[key]
+		if !found {
+			return "", false
+		}
+
+		element = value
+	}
+
+	// The keys of a map are sorted when it is written as JSON, so objects with
+	// the same contents have the same text. Numbers are written in a single
+	// form, so 1 and 1.0 are the same value.
+	b, err := json.Marshal(normalizeNumbers(element, func(n json.Number) any {
+		return json.Number(numberText(n))
+	}))
+
+	return string(b), err == nil
+}
+
+// verifyElements reports an error if the rules for the values of an array are
+// used with another type, if the limits for the contains rule are set without a
+// contains validator, or if the unique_by field is not a field of the structure
+// of the array values.
+func (i *Item) verifyElements() error {
+	if i.ItemType != TypeArray {
+		if i.Unique || i.UniqueBy != "" || i.Contains != nil || i.HasMinContains || i.HasMaxContains {
+			return ErrInvalidValidator.Context("unique").Value(i.ItemType.String())
+		}
+
+		return nil
+	}
+
+	if (i.HasMinContains || i.HasMaxContains) && i.Contains == nil {
+		return ErrInvalidValidator.Context("contains").Value("missing contains validator")
+	}
+
+	if s := i.BaseType.structure(); i.UniqueBy != "" && s != nil && !s.hasField(i.UniqueBy) {
+		return ErrInvalidValidator.Context("unique_by").Value(i.UniqueBy)
+	}
+
+	return nil
+}
+
+// elementRules returns the descriptions of the rules for the values of an array
+// as a whole.
+func (i *Item) elementRules() []string {
+	list := []string{}
+
+	if i.Unique {
+		list = append(list, "unique values")
+	}
+
+	if i.UniqueBy != "" {
+		list = append(list, "unique by "+i.UniqueBy)
+	}
+
+	if i.Contains != nil {
+		count := describeRange(true, i.minContains(), i.HasMaxContains, i.MaxContains)
+		list = append(list, "containing "+count+" "+i.Contains.summary())
+	}
+
+	return list
+}
+
+// elementAttributes returns the validator source for the rules for the values
+// of an array as a whole.
+func (i *Item) elementAttributes() []string {
+	list := []string{}
+
+	if i.Unique {
+		list = append(list, "unique")
+	}
+
+	if i.UniqueBy != "" {
+		list = append(list, "unique_by="+quoteValue(i.UniqueBy))
+	}
+
+	if i.Contains != nil {
+		list = append(list, "contains=("+i.Contains.definition()+")")
+	}
+
+	if i.HasMinContains {
+		list = append(list, "mincontains="+strconv.Itoa(i.MinContains))
+	}
+
+	if i.HasMaxContains {
+		list = append(list, "maxcontains="+strconv.Itoa(i.MaxContains))
+	}
+
+	return list
+}
+
+// hasElementRules reports if the array has rules for its values as a whole.
+func (i *Item) hasElementRules() bool {
+	return i.Unique || i.UniqueBy != "" || i.Contains != nil
+}
+
+// containsCount returns the number of values of a new array that are created
+// using the contains validator, which is a random number in the allowed range.
+func (g *Generator) containsCount(i *Item, count int) int {
+	if i.Contains == nil {
+		return 0
+	}
+
+	lo, hi := i.minContains(), count
+	if i.HasMaxContains {
+		hi = min(hi, i.MaxContains)
+	}
+
+	if lo >= hi {
+		return lo
+	}
+
+	return lo + g.rand.Intn(hi-lo+1)
+}
+
+// elementMutations adds the mutations for the rules for the values of an array
+// as a whole: a duplicate of the first value, and the removal or addition of
+// values that match the contains validator. The mutations that cause another
+// error, such as a length error, are removed by the caller.
+func (g *Generator) elementMutations(i *Item, path []any, depth int, array []any) {
+	// The last value is replaced by a copy of the first, so the length of the
+	// array does not change.
+	if len(array) > 1 && (i.Unique || i.UniqueBy != "") {
+		duplicate := copyValue(array).([]any)
+		duplicate[len(array)-1] = copyValue(array[0])
+		g.mutate(path, duplicate, "unique", ErrDuplicateValue.Context(i.Name).Value(len(array)-1))
+	}
+
+	if i.Contains == nil {
+		return
+	}
+
+	// Remove the matching values, or add another copy of a matching value.
+	rest := []any{}
+	count := 0
+
+	for _, element := range array {
+		if i.Contains.validateValue(element, depth+1) == nil {
+			count++
+
+			continue
+		}
+
+		rest = append(rest, element)
+	}
+
+	if lo := i.minContains(); lo > 0 {
+		g.mutate(path, rest, "mincontains", ErrContainsOutOfRange.Context(i.Name).Value(0).Expected(lo))
+	}
+
+	if i.HasMaxContains {
+		long := copyValue(array).([]any)
+
+		for ; count <= i.MaxContains; count++ {
+			extra, err := g.value(i.Contains, nil, depth+1, false)
+			if err != nil {
+				return
+			}
+
+			long = append(long, extra)
+		}
+
+		g.mutate(path, long, "maxcontains", ErrContainsOutOfRange.Context(i.Name).Value(count).Expected(i.MaxContains))
+	}
+}
+
+// validArray reports if a new array is valid, once it is written as JSON.
+func (i *Item) validArray(array []any, depth int) bool {
+	doc, err := jsonObject(map[string]any{"value": array})
+
+	return err == nil && i.validateValue(doc["value"], depth) == nil
+}
@@ -2,8 +2,10 @@ package validator
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"text/scanner"
+	"unicode/utf8"
 )
 
 type tokenizer struct {
@@ -52,23 +54,48 @@ func (t *tokenizer) peek(offset int) string {
 
 func (t *tokenizer) pos() string {
 	i := t.position - 1
+	if i < 0 {
+		i = 0
+	}
+
+	if i >= len(t.tokens) {
+		return "end of input"
+	}
 
 	return fmt.Sprintf("line %d, column %d", t.tokens[i].line, t.tokens[i].column)
 }
 
-func Compile(src string) (*Item, error) {
+// Report if the current token immediately follows the previous token in the
+// source, with no intervening spaces.
+func (t *tokenizer) adjacent() bool {
+	if t.position < 1 || t.position >= len(t.tokens) {
+		return false
+	}
+
+	previous := t.tokens[t.position-1]
+	current := t.tokens[t.position]
+
+	return previous.line == current.line &&
+		previous.column+utf8.RuneCountInString(previous.spelling) == current.column
+}
+
+// tokenize breaks the source text into tokens, using the Go scanner. Comments are
+// skipped, and quoted strings are kept as a single token including the quotes. If
+// the scanner reports an error, such as an unterminated string, the first error
+// is returned along with the tokens that were found.
+func tokenize(src string) (*tokenizer, error) {
 	var (
-		s      scanner.Scanner
-		errors []error
+		s   scanner.Scanner
+		err error
 	)
 
-	src = UpdateLineEndings(src)
-
 	s.Init(strings.NewReader(src))
 
-	// Redirect any lexical scanning errors to the tokenizer log, if enabled.
+	// Record the first lexical scanning error, with its position.
 	s.Error = func(s *scanner.Scanner, msg string) {
-		errors = append(errors, NewError(msg))
+		if err == nil {
+			err = ErrSyntaxError.Context(fmt.Sprintf("line %d, column %d", s.Pos().Line, s.Pos().Column)).Value(msg)
+		}
 	}
 
 	s.Filename = "Input"
@@ -86,7 +113,29 @@ func Compile(src string) (*Item, error) {
 		})
 	}
 
-	return compileItem(tokenizer)
+	return tokenizer, err
+}
+
+func Compile(src string) (*Item, error) {
+	// Tokens that cannot be scanned are reported when they are compiled, so the
+	// scanner error is not used.
+	tokenizer, _ := tokenize(UpdateLineEndings(src))
+
+	item, err := compileItem(tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only line endings are permitted after the item definition.
+	for tokenizer.peek(0) == ";" {
+		tokenizer.next()
+	}
+
+	if next := tokenizer.next(); next != "" {
+		return nil, ErrSyntaxError.Context(tokenizer.pos()).Value(next).Expected(";")
+	}
+
+	return item, nil
 }
 
 func UpdateLineEndings(src string) string {
@@ -122,104 +171,115 @@ func UpdateLineEndings(src string) string {
 func compileItem(t *tokenizer) (*Item, error) {
 	item := &Item{}
 
-	// Is it a structure? Compile all the fields.
-	if t.peek(0) == "{" {
-		t.move(1)
+	// If the item does not start with a type, the first token is the name of
+	// the item. A quoted string can be used for names that are not valid
+	// identifiers, or that are the same as a reserved type word.
+	if isNameStart(t) {
+		item.Name = unquote(t.next())
 
-		err := compileObject(t, item)
+		if next := t.peek(0); next == ":" || next == ";" {
+			t.next()
 
-		return item, err
+			return nil, ErrUnsupportedType.Context(t.pos()).Value(item.Name)
+		}
 	}
 
-	if err := compileType(t, item); err != nil {
+	if err := compileDefinition(t, item, item); err != nil {
 		return nil, err
 	}
 
-	if t.peek(0) == ";" {
-		return item, nil
-	}
-
-	next := t.next()
+	return item, nil
+}
 
-	// If this is the end of a map key definition, we're done.
-	if next == "]" {
-		return item, nil
+// compileDefinition compiles a type declaration and the optional list of
+// attributes that follow it. The owner is the named item being compiled,
+// which is used to support the older form where the name follows the
+// array or pointer prefix, as in "[]name int".
+func compileDefinition(t *tokenizer, item *Item, owner *Item) error {
+	if err := compileType(t, item, owner); err != nil {
+		return err
 	}
 
-	// If this is a map type and we've hit the end of the key declaration, we're done.
-	if item.ItemType == TypeMap && next == "]" {
-		return item, nil
-	}
+	if t.peek(0) == ":" {
+		t.next()
 
-	if next == ":" {
-		if err := compileAttributes(t, item); err != nil {
-			return nil, err
-		}
-
-		return item, nil
+		return compileAttributes(t, item)
 	}
 
-	// Is it a map? Compile the key and value attributes.
-	if item.ItemType == TypeMap && next == "[" {
-		key, err := compileItem(t)
-		if err != nil {
-			return nil, err
-		}
-
-		if key.ItemType != TypeString {
-			return nil, ErrUnsupportedType.Context(t.pos()).Value(key.ItemType.String()).Expected("string")
-		}
+	return nil
+}
 
-		key.ItemType = TypeMap
-		key.Name = item.Name
+// compileAttributes reads the tokens up to the end of the current declaration
+// and parses them as a tag string. The terminating token is not consumed. Tokens
+// that were adjacent in the source are kept adjacent in the tag string, so values
+// like "-5" or "1h30m" are not broken apart. Tokens inside parentheses do not end
+// the declaration, so a value can contain a type declaration.
+//
+// Outside of parentheses, space is only allowed around the "=" and "," of each
+// attribute, so each value is a single word, quoted string, or parenthesized
+// list. Anything else, such as a missing ";" before the next declaration, is a
+// syntax error.
+func compileAttributes(t *tokenizer, item *Item) error {
+	text := ""
+	nesting := 0
+	previous := ""
 
-		next := t.peek(-1)
-		if next != "]" {
-			return nil, ErrSyntaxError.Context(t.pos()).Value(next).Expected("]")
+	for {
+		next := t.peek(0)
+		if next == "" || nesting == 0 && (next == ";" || next == "]" || next == "}" || next == ")") {
+			break
 		}
 
-		key.BaseType, err = compileItem(t)
+		if text != "" && !t.adjacent() {
+			if nesting == 0 && !isAttributeSeparator(previous) && !isAttributeSeparator(next) {
+				t.next()
 
-		return key, err
-	}
+				return ErrSyntaxError.Context(t.pos()).Value(next).Expected(",", ";")
+			}
 
-	// Is it a structure? Compile all the fields.
-	if next == "{" {
-		var err error
+			text += " "
+		}
 
-		if item.ItemType == TypeArray && item.BaseType != nil && item.BaseType.ItemType == TypeStruct {
-			err = compileObject(t, item.BaseType)
-		} else {
-			err = compileObject(t, item)
+		switch next {
+		case "(":
+			nesting++
+		case ")":
+			nesting = max(nesting-1, 0)
 		}
 
-		return item, err
+		previous = t.next()
+		text += previous
 	}
 
-	return nil, ErrSyntaxError.Context(t.pos()).Value(next).Expected(";", ":", "{")
+	return item.ParseTag(text)
 }
 
-func compileAttributes(t *tokenizer, item *Item) error {
-	text := ""
-
-	next := t.next()
-	for next != ";" && next != "]" {
-		text += next + " "
-		next = t.next()
-	}
-
-	return item.ParseTag(text)
+// isAttributeSeparator reports if a token separates the parts of an attribute
+// list, so it can have space before or after it.
+func isAttributeSeparator(spelling string) bool {
+	return spelling == "=" || spelling == ","
 }
 
 func compileObject(t *tokenizer, item *Item) error {
 	item.ItemType = TypeStruct
 
 	for {
-		// Compile the field
-		if t.peek(0) == "}" {
-			return nil
+		switch t.peek(0) {
+		case ";":
+			t.next()
+
+			continue
+
+		case "}":
+			t.next()
+
+			return item.verifyFieldRules()
+
+		case "":
+			return ErrSyntaxError.Context(t.pos()).Expected("}")
 		}
 
+		// Compile the field
 		field, err := compileItem(t)
 		if err != nil {
 			return err
@@ -227,100 +287,188 @@ func compileObject(t *tokenizer, item *Item) error {
 
 		item.Fields = append(item.Fields, field)
 
-		if t.peek(0) == "}" {
+		if next := t.peek(0); next != ";" && next != "}" {
 			t.next()
 
-			return nil
-		}
-
-		if t.peek(0) == ";" {
-			t.move(1)
+			return ErrSyntaxError.Context(t.pos()).Value(next).Expected(";", ":", "{")
 		}
 	}
 }
 
-func compileType(t *tokenizer, item *Item) error {
-	array := false
-	ptr := false
-	name := ""
+func compileType(t *tokenizer, item *Item, owner *Item) error {
 	next := t.next()
 
-	if next == "*" {
-		ptr = true
+	switch next {
+	case "*", "[":
+		if next == "[" {
+			if t.peek(0) != "]" {
+				return ErrSyntaxError.Context(t.pos()).Value(t.peek(0)).Expected("]")
+			}
 
-		next = t.next()
-	}
+			t.next()
 
-	if next == "[" && t.peek(0) == "]" {
-		array = true
+			item.ItemType = TypeArray
+		} else {
+			item.ItemType = TypePointer
+			item.Nullable = true
+		}
 
-		t.move(1) // consume "]"
+		// Support the older form where the name follows the prefix.
+		if owner.Name == "" && isNameStart(t) {
+			owner.Name = unquote(t.next())
+		}
 
-		next = t.next() // get type name
-	}
+		item.BaseType = &Item{}
+
+		return compileType(t, item.BaseType, owner)
+
+	case "{":
+		return compileObject(t, item)
 
-	// Is it a reserved type word? If not, assume it's the item name, and
-	// fetch the type name that follows it.
-	if _, ok := TypeNamesMap[next]; next != "{" && !ok {
-		name = next
+	case "(":
+		return compileTuple(t, item)
 
-		next = t.next()
+	case "@":
+		item.ItemType = TypeStruct
+		item.Alias = unquote(t.next())
 
-		if next == ":" || next == ";" {
-			return ErrUnsupportedType.Context(t.pos()).Value(name)
+		if item.Alias == "" {
+			return ErrSyntaxError.Context(t.pos()).Expected("alias name")
 		}
+
+		return nil
+	}
+
+	kind, ok := TypeNamesMap[next]
+	if !ok {
+		return ErrUnsupportedType.Value(next).Context(t.pos())
 	}
 
-	item.Name = name
+	item.ItemType = kind
 
-	if next == "{" {
-		// Special case for array of structs
-		if array {
-			item.ItemType = TypeArray
-			item.BaseType = &Item{
-				ItemType: TypeStruct,
-			}
-		} else {
-			item.ItemType = TypeStruct
+	if kind == TypeUnion {
+		return compileUnion(t, item)
+	}
+
+	// A custom type is followed by the name of the registered type, which is
+	// a quoted string or a word that is not the name of a built-in type.
+	if kind == TypeCustom {
+		name := t.next()
+		if !strings.HasPrefix(name, "\"") && (!identifierPattern.MatchString(name) || isTypeStart(name)) {
+			return ErrSyntaxError.Context(t.pos()).Value(name).Expected("type name")
 		}
 
-		t.move(-1)
+		item.TypeName = unquote(name)
+		if item.TypeName == "" {
+			return ErrSyntaxError.Context(t.pos()).Expected("type name")
+		}
 
 		return nil
-	} else {
-		if kind, ok := TypeNamesMap[next]; ok {
-			if array {
-				item.ItemType = TypeArray
-				item.BaseType = NewType(kind)
-			} else if ptr {
-				item.ItemType = TypePointer
-				item.BaseType = NewType(kind)
-			} else {
-				item.ItemType = kind
+	}
+
+	// Is it a map? Compile the key and value attributes. The attributes of the
+	// key declaration are the attributes of the map itself.
+	if kind == TypeMap && t.peek(0) == "[" {
+		t.next()
+
+		keyType := &Item{}
+		if err := compileType(t, keyType, keyType); err != nil {
+			return err
+		}
+
+		if !isKeyType(keyType.ItemType) {
+			return ErrUnsupportedType.Context(t.pos()).Value(keyType.ItemType.String()).Expected("string", "int", "uuid", "time", "custom")
+		}
+
+		// The attributes are parsed once the item is a map, so they can
+		// include the rules for the keys. String keys do not need a key
+		// validator unless there are rules for the keys.
+		key := &Item{
+			Name:     item.Name,
+			ItemType: TypeMap,
+			BaseType: &Item{},
+		}
+
+		if keyType.ItemType != TypeString {
+			key.KeyType = keyType
+		}
+
+		if t.peek(0) == ":" {
+			t.next()
+
+			if err := compileAttributes(t, key); err != nil {
+				return err
 			}
+		}
 
-			return nil
+		if next := t.next(); next != "]" {
+			return ErrSyntaxError.Context(t.pos()).Value(next).Expected("]")
 		}
+
+		if err := compileDefinition(t, key.BaseType, key.BaseType); err != nil {
+			return err
+		}
+
+		*item = *key
 	}
 
-	return ErrUnsupportedType.Value(next).Context(t.pos())
+	return nil
 }
 
-func DeepCopy(src *Item) *Item {
-	if src == nil {
-		return nil
+// isTypeStart returns true if the token is the start of a type declaration,
+// as opposed to the name of an item.
+func isTypeStart(spelling string) bool {
+	switch spelling {
+	case "*", "[", "{", "@", "(":
+		return true
 	}
 
-	result := &Item{
-		Name:     src.Name,
-		ItemType: src.ItemType,
-		Fields:   make([]*Item, len(src.Fields)),
-		BaseType: DeepCopy(src.BaseType),
+	_, ok := TypeNamesMap[spelling]
+
+	return ok
+}
+
+// isNameStart returns true if the next token is the name of an item rather
+// than the start of its type. A type word is only a name when it is followed by
+// a type, so a field can be called "any" or "union" without quotes. The words
+// "map" and "union" followed by their own brackets or parentheses are types.
+func isNameStart(t *tokenizer) bool {
+	next := t.peek(0)
+	if next == "" {
+		return false
+	}
+
+	if !isTypeStart(next) {
+		return true
+	}
+
+	if _, ok := TypeNamesMap[next]; !ok {
+		return false
+	}
+
+	switch following := t.peek(1); {
+	case next == "map" && following == "[", next == "union" && following == "(":
+		return false
+
+	default:
+		return isTypeStart(following)
 	}
+}
 
-	for i, field := range src.Fields {
-		result.Fields[i] = DeepCopy(field)
+// unquote removes the quotes from a quoted string token. If the token is
+// not a quoted string, it is returned unchanged.
+func unquote(spelling string) string {
+	if len(spelling) >= 2 && strings.HasPrefix(spelling, "\"") && strings.HasSuffix(spelling, "\"") {
+		if text, err := strconv.Unquote(spelling); err == nil {
+			return text
+		}
 	}
 
-	return result
+	return spelling
+}
+
+// DeepCopy returns a copy of the item that is independent of the original,
+// the same as the item's Copy() method.
+func DeepCopy(src *Item) *Item {
+	return src.Copy()
 }
@@ -0,0 +1,266 @@
+package validator
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// The interfaces that a type can implement to decode or encode its own JSON value.
+var (
+	jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// TypeHandler describes how a custom Go type is represented in JSON, so it can
+// be validated. A handler is registered for a Go type using RegisterType().
+type TypeHandler interface {
+	// Name returns the name of the type, used in place of the type when the
+	// validator is written as JSON or in the validator definition language.
+	Name() string
+
+	// Shape returns the type of the JSON value used to represent the type,
+	// such as TypeString or TypeFloat. The JSON value must be valid for this
+	// type before it is passed to the Value() method.
+	Shape() Type
+
+	// Value converts a value to the Go type. The value is either a value from
+	// the JSON being validated, where numbers are float64 values as they are for
+	// encoding/json, or a minimum, maximum, or enumerated value for the validator,
+	// which are usually strings. It returns an error if the value is not valid
+	// for the type.
+	Value(v any) (any, error)
+
+	// Compare compares two values returned by the Value() method. It returns
+	// a negative number if a is less than b, zero if they are equal, or a
+	// positive number if a is greater than b.
+	Compare(a, b any) int
+}
+
+// A customType is a Go type used by custom type validators. The handler is nil
+// for a type that implements json.Unmarshaler or encoding.TextUnmarshaler.
+type customType struct {
+	goType  reflect.Type
+	handler TypeHandler
+}
+
+// customTypes holds the types used by custom type validators, stored by the
+// type name used in the validator. Access to the map is serialized by a mutex.
+var customTypes = map[string]customType{}
+
+var customTypesLock sync.Mutex
+
+// RegisterType registers a handler for a Go type. When New() finds a value of
+// this type, it creates a custom type validator that uses the handler to check
+// the JSON value, and the minimum, maximum, and enumerated values, if any. The
+// handler's name is used for the type in the JSON form of the validator and in
+// the validator definition language, and must not be the name of a built-in type.
+// A Go type can only have one handler; registering a handler with the same name
+// replaces it, and registering one with another name is an error.
+func RegisterType(t reflect.Type, handler TypeHandler) error {
+	if t == nil || handler == nil {
+		return ErrUnsupportedType.Value(t)
+	}
+
+	name := handler.Name()
+	if _, err := TypeFromString(name); err == nil || name == "" {
+		return ErrInvalidName.Value(name)
+	}
+
+	if _, found := TypeNamesMap[name]; found {
+		return ErrInvalidName.Value(name)
+	}
+
+	switch shape := handler.Shape(); shape {
+	case TypeInvalid, TypeStruct, TypeArray, TypePointer, TypeMap, TypeCustom:
+		return ErrUnsupportedType.Context(name).Value(shape.String())
+	}
+
+	customTypesLock.Lock()
+	defer customTypesLock.Unlock()
+
+	// With one handler for each Go type, the handler found for a type does not
+	// depend on the order of the registry.
+	for existing, custom := range customTypes {
+		if custom.handler != nil && custom.goType == t && existing != name {
+			return ErrNameAlreadyExists.Context(name).Value(existing)
+		}
+	}
+
+	customTypes[name] = customType{goType: t, handler: handler}
+
+	return nil
+}
+
+// RegisterUnmarshaler registers the type of the given value as a custom type,
+// using the import path and name of the Go type, such as "net.IP" or
+// "net/netip.Addr", so types with the same name in different packages are
+// kept apart. The type, or a pointer to
+// the type, must implement json.Unmarshaler or encoding.TextUnmarshaler. The
+// New() function registers these types automatically, but a validator read
+// using NewJSON() or Compile() needs the type to be registered before values
+// can be validated.
+func RegisterUnmarshaler(v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil || !isUnmarshaler(t) {
+		return ErrUnsupportedType.Value(v)
+	}
+
+	storeCustomType(customTypeName(t), customType{goType: t})
+
+	return nil
+}
+
+// isUnmarshaler reports if the type decodes its own JSON value. Pointer types
+// are not unmarshalers themselves; the type they point to is checked instead.
+func isUnmarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	p := reflect.PointerTo(t)
+
+	return p.Implements(jsonUnmarshalerType) || p.Implements(textUnmarshalerType)
+}
+
+// customTypeName returns the name used for a Go type in the custom type registry.
+// A named type is identified by its import path and name, because the package
+// name used by reflect.Type.String() is not unique.
+func customTypeName(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return t.PkgPath() + "." + t.Name()
+}
+
+// storeCustomType adds a type to the custom type registry.
+func storeCustomType(name string, t customType) {
+	customTypesLock.Lock()
+	defer customTypesLock.Unlock()
+
+	customTypes[name] = t
+}
+
+// findCustomType returns the type registered with the given name.
+func findCustomType(name string) (customType, bool) {
+	customTypesLock.Lock()
+	defer customTypesLock.Unlock()
+
+	t, found := customTypes[name]
+
+	return t, found
+}
+
+// findTypeHandler returns the handler registered for a Go type, if any. There is
+// at most one handler for each type.
+func findTypeHandler(t reflect.Type) (TypeHandler, bool) {
+	customTypesLock.Lock()
+	defer customTypesLock.Unlock()
+
+	for _, custom := range customTypes {
+		if custom.handler != nil && custom.goType == t {
+			return custom.handler, true
+		}
+	}
+
+	return nil, false
+}
+
+// validateCustom validates a value using the type registered for the item.
+// If the type has a handler, the handler checks the value. Otherwise, the
+// value is decoded into a new value of the registered type, the same way
+// encoding/json would. A json.Unmarshaler is given the JSON text of the value.
+// A encoding.TextUnmarshaler is given the text of a string value, and any other
+// value is invalid, except null, which encoding/json ignores.
+func (i *Item) validateCustom(v any) error {
+	custom, found := findCustomType(i.TypeName)
+	if !found {
+		return ErrUnsupportedType.Context(i.Name).Value(i.TypeName)
+	}
+
+	if v == nil && i.Nullable {
+		return nil
+	}
+
+	if custom.handler != nil {
+		return i.validateHandler(custom.handler, v)
+	}
+
+	target := reflect.New(custom.goType).Interface()
+
+	if unmarshaler, ok := target.(json.Unmarshaler); ok {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ErrInvalidData.Context(i.Name).Value(v).Cause(err)
+		}
+
+		if err := unmarshaler.UnmarshalJSON(b); err != nil {
+			return ErrInvalidData.Context(i.Name).Value(v).Cause(err)
+		}
+
+		return nil
+	}
+
+	unmarshaler, ok := target.(encoding.TextUnmarshaler)
+	if !ok {
+		return ErrUnsupportedType.Context(i.Name).Value(i.TypeName)
+	}
+
+	switch value := v.(type) {
+	case nil:
+		return nil
+
+	case string:
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return ErrInvalidData.Context(i.Name).Value(v).Cause(err)
+		}
+
+		return nil
+	}
+
+	return ErrInvalidData.Context(i.Name).Value(v)
+}
+
+// validateHandler validates a value using a registered type handler. The value
+// must have the shape of the type, must be accepted by the handler, and must be
+// in range and match one of the enumerated values, if there are any. A JSON
+// number is passed to the handler as a float64.
+func (i *Item) validateHandler(handler TypeHandler, v any) error {
+	shape := &Item{Name: i.Name, ItemType: handler.Shape()}
+	if err := shape.validateValue(v, 0); err != nil {
+		return err
+	}
+
+	value, err := handler.Value(floatNumbers(v))
+	if err != nil {
+		return ErrInvalidData.Context(i.Name).Value(v).Cause(err)
+	}
+
+	if i.HasMinValue {
+		if limit, err := handler.Value(i.MinValue); err == nil && handler.Compare(value, limit) < 0 {
+			return ErrValueOutOfRange.Context(i.Name).Value(v)
+		}
+	}
+
+	if i.HasMaxValue {
+		if limit, err := handler.Value(i.MaxValue); err == nil && handler.Compare(value, limit) > 0 {
+			return ErrValueOutOfRange.Context(i.Name).Value(v)
+		}
+	}
+
+	if len(i.Enums) == 0 {
+		return nil
+	}
+
+	for _, enum := range i.Enums {
+		if enumValue, err := handler.Value(enum); err == nil && handler.Compare(value, enumValue) == 0 {
+			return nil
+		}
+	}
+
+	return ErrInvalidEnumeratedValue.Context(i.Name).Value(v).Expected(i.Enums)
+}
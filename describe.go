@@ -0,0 +1,584 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The indentation used for each nesting level of a description.
+const describeIndent = "    "
+
+// Names used to describe each of the value types.
+var typeDescriptions = map[Type]string{
+	TypeString:   "string",
+	TypeInt:      "integer",
+	TypeFloat:    "number",
+	TypeBool:     "boolean",
+	TypeUUID:     "UUID",
+	TypeTime:     "time",
+	TypeDuration: "duration",
+	TypeList:     "comma-separated list",
+	TypeAny:      "any value",
+}
+
+// Phrases used to describe each of the field group rules.
+var groupDescriptions = map[string]string{
+	GroupOneOf:  "exactly one of",
+	GroupAnyOf:  "at least one of",
+	GroupAllOf:  "all of",
+	GroupNoneOf: "none of",
+}
+
+// Phrases used to describe each of the cross-field comparison rules.
+var fieldRuleDescriptions = map[string]string{
+	RuleGreaterField:      "greater than",
+	RuleGreaterEqualField: "at least",
+	RuleLessField:         "less than",
+	RuleLessEqualField:    "at most",
+	RuleEqualField:        "equal to",
+	RuleNotEqualField:     "not equal to",
+}
+
+// Describe returns an English description of the rules enforced by the validator.
+// Each field of a structure is described on its own line, indented under the
+// line that describes the structure, as in:
+//
+//	staff: array of at least 1 Person
+//	    name: required string, with length between 1 and 100
+//	    age: required integer, between 18 and 65
+//
+// Structures created from Go types are described using the name of the type.
+// A structure that contains itself is only described once.
+func (i *Item) Describe() string {
+	if i == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	i.describe(&b, 0, map[*Item]bool{})
+
+	return b.String()
+}
+
+// Markdown returns a Markdown table that describes the rules enforced by the
+// validator, with one row for each field. Nested fields are identified by their
+// path, using "[]" for the elements of an array and ".*" for the values of a map,
+// as in "staff[].name".
+func (i *Item) Markdown() string {
+	if i == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	b.WriteString("| Field | Type | Required | Rules |\n")
+	b.WriteString("| ----- | ---- | -------- | ----- |\n")
+
+	// A structure (or pointer to a structure) is written as a row for each
+	// field. Any other validator is written as a single row.
+	top := i.resolve()
+	for top != nil && top.ItemType == TypePointer {
+		top = top.BaseType.resolve()
+	}
+
+	if top != nil && top.ItemType == TypeStruct {
+		i.markdownFields(&b, "", map[*Item]bool{})
+	} else {
+		i.markdownRow(&b, "", map[*Item]bool{})
+	}
+
+	return b.String()
+}
+
+// describe writes the description of this item and any nested fields.
+func (i *Item) describe(b *strings.Builder, depth int, ancestors map[*Item]bool) {
+	b.WriteString(strings.Repeat(describeIndent, depth))
+
+	if i.Name != "" {
+		b.WriteString(i.Name)
+		b.WriteString(": ")
+	}
+
+	b.WriteString(i.summary())
+	b.WriteString("\n")
+
+	// If there is a structure in this item, describe its fields, unless we
+	// are already describing the structure.
+	s := i.structure()
+	if s == nil || ancestors[s] {
+		return
+	}
+
+	ancestors[s] = true
+
+	for _, field := range s.Fields {
+		field.describe(b, depth+1, ancestors)
+	}
+
+	delete(ancestors, s)
+}
+
+func (i *Item) markdownFields(b *strings.Builder, prefix string, ancestors map[*Item]bool) {
+	s := i.structure()
+	if s == nil || ancestors[s] {
+		return
+	}
+
+	ancestors[s] = true
+
+	for _, field := range s.Fields {
+		field.markdownRow(b, prefix+field.Name, ancestors)
+	}
+
+	delete(ancestors, s)
+}
+
+func (i *Item) markdownRow(b *strings.Builder, path string, ancestors map[*Item]bool) {
+	required := "no"
+	if i.Required {
+		required = "yes"
+	}
+
+	cells := []string{path, i.typePhrase(), required, strings.Join(i.rules(), ", ")}
+	for n, cell := range cells {
+		cells[n] = strings.ReplaceAll(cell, "|", "\\|")
+	}
+
+	b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+
+	i.markdownFields(b, path+i.containerPath()+".", ancestors)
+}
+
+// containerPath returns the path suffix that leads from this item to the
+// structure it contains, if any.
+func (i *Item) containerPath() string {
+	path := ""
+
+	for item := i.resolve(); item != nil; item = item.BaseType.resolve() {
+		switch item.ItemType {
+		case TypeArray:
+			path += "[]"
+
+		case TypeMap:
+			path += ".*"
+
+		case TypePointer:
+
+		default:
+			return path
+		}
+	}
+
+	return path
+}
+
+// summary returns the one-line description of this item.
+func (i *Item) summary() string {
+	text := i.typePhrase()
+
+	if i.describeNullable() {
+		text = "nullable " + text
+	}
+
+	if i.Required {
+		text = "required " + text
+	}
+
+	if rules := i.rules(); len(rules) > 0 {
+		text += ", " + strings.Join(rules, ", ")
+	}
+
+	return text
+}
+
+// valueSummary describes the values accepted by a validator that may be nil,
+// which accepts any value.
+func (i *Item) valueSummary() string {
+	if i == nil {
+		return typeDescriptions[TypeAny]
+	}
+
+	return i.summary()
+}
+
+// elementPhrase describes the type of the elements of an array or the values
+// of a map, including if they can be null.
+func (i *Item) elementPhrase() string {
+	if i != nil && i.describeNullable() {
+		return "nullable " + i.typePhrase()
+	}
+
+	return i.typePhrase()
+}
+
+// describeNullable reports if the description says that the value can be null.
+// Pointers, slices, and maps created by New() are nullable, as the Go values can
+// be nil, so this is only described for other types.
+func (i *Item) describeNullable() bool {
+	switch i.ItemType {
+	case TypePointer, TypeArray, TypeMap, TypeAny:
+		return false
+	}
+
+	return i.Nullable
+}
+
+// typePhrase describes the type of the item, such as "array of at least 1 Person".
+func (i *Item) typePhrase() string {
+	i = i.resolve()
+	if i == nil {
+		return typeDescriptions[TypeAny]
+	}
+
+	switch i.ItemType {
+	case TypePointer:
+		return i.BaseType.typePhrase()
+
+	case TypeArray:
+		count := ""
+		if !(i.HasMinLength && i.MinLength == 0 && !i.HasMaxLength) {
+			count = describeRange(i.HasMinLength, i.MinLength, i.HasMaxLength, i.MaxLength)
+		}
+
+		if count != "" {
+			count += " "
+		}
+
+		return "array of " + count + i.BaseType.elementPhrase()
+
+	case TypeMap:
+		if i.KeyType != nil && i.KeyType.ItemType != TypeString {
+			return "map of " + i.KeyType.typePhrase() + " keys to " + i.BaseType.elementPhrase()
+		}
+
+		return "map of " + i.BaseType.elementPhrase()
+
+	case TypeStruct:
+		if name := i.typeName(); name != "" {
+			return name
+		}
+
+		return "object"
+
+	case TypeCustom:
+		return i.TypeName
+
+	case TypeUnion:
+		return i.describeUnion()
+
+	case TypeTuple:
+		return i.describeTuple()
+
+	case TypeFloat:
+		if i.Decimal {
+			return "exact decimal number"
+		}
+	}
+
+	if text, ok := typeDescriptions[i.ItemType]; ok {
+		return text
+	}
+
+	return i.ItemType.String()
+}
+
+// rules returns the descriptions of the rules for this item, other than the
+// rules already included in the type phrase.
+func (i *Item) rules() []string {
+	i = i.resolve()
+	if i == nil {
+		return nil
+	}
+
+	list := []string{}
+
+	switch i.ItemType {
+	case TypePointer:
+		list = append(list, i.BaseType.rules()...)
+
+	case TypeArray:
+		if elements := i.BaseType.rules(); len(elements) > 0 && i.BaseType.structure() == nil {
+			list = append(list, "each "+strings.Join(elements, " and "))
+		}
+
+		list = append(list, i.elementRules()...)
+
+	case TypeMap:
+		if len(i.Enums) > 0 {
+			list = append(list, "keys "+i.describeEnums())
+		}
+
+		if i.Pattern != "" {
+			list = append(list, "keys matching "+strconv.Quote(i.Pattern))
+		}
+
+		if i.KeyType != nil {
+			for _, rule := range i.KeyType.rules() {
+				list = append(list, "keys "+rule)
+			}
+		}
+
+		if text := describeRange(i.HasMinLength, i.MinLength, i.HasMaxLength, i.MaxLength); text != "" {
+			list = append(list, text+" keys")
+		}
+
+		if values := i.BaseType.rules(); len(values) > 0 && i.BaseType.structure() == nil {
+			list = append(list, "values "+strings.Join(values, " and "))
+		}
+
+	case TypeStruct:
+		if i.AllowForeignKey {
+			list = append(list, "other fields allowed")
+		}
+
+		if text := describeMatch(i.FieldMatch); text != "" {
+			list = append(list, "field names "+text)
+		}
+
+		for _, group := range i.Groups {
+			list = append(list, groupDescriptions[group.Op]+" "+strings.Join(group.Fields, ", "))
+		}
+
+		for _, text := range i.Expressions {
+			list = append(list, "satisfies "+strconv.Quote(text))
+		}
+
+		for _, property := range i.PatternProperties {
+			list = append(list, "other fields matching "+strconv.Quote(property.Pattern)+" are "+property.Value.valueSummary())
+		}
+
+		if i.AdditionalProperties != nil {
+			list = append(list, "other fields are "+i.AdditionalProperties.valueSummary())
+		}
+
+		if i.TypeName != "" {
+			list = append(list, "checked by the "+i.TypeName+" type")
+		}
+
+	case TypeString:
+		if text := describeRange(i.HasMinLength, i.MinLength, i.HasMaxLength, i.MaxLength); text != "" {
+			list = append(list, "with length "+text)
+		}
+
+		if i.Pattern != "" {
+			list = append(list, "matching "+strconv.Quote(i.Pattern))
+		}
+
+		if len(i.Enums) > 0 {
+			list = append(list, i.describeEnums())
+		}
+
+	case TypeList:
+		if text := describeRange(i.HasMinLength, i.MinLength, i.HasMaxLength, i.MaxLength); text != "" {
+			list = append(list, "with "+text+" elements")
+		}
+
+		if len(i.Enums) > 0 {
+			list = append(list, "each "+i.describeEnums())
+		}
+
+	case TypeInt, TypeFloat, TypeDuration:
+		if text := describeBounds(i); text != "" {
+			list = append(list, text)
+		}
+
+		if i.MultipleOf != nil {
+			list = append(list, "multiple of "+formatValue(i.MultipleOf))
+		}
+
+		if len(i.Enums) > 0 && i.ItemType != TypeDuration {
+			list = append(list, i.describeEnums())
+		}
+
+	case TypeCustom:
+		if text := describeValues(i, "at least", "at most"); text != "" {
+			list = append(list, text)
+		}
+
+		if len(i.Enums) > 0 {
+			list = append(list, i.describeEnums())
+		}
+
+	case TypeTime:
+		if text := describeValues(i, "no earlier than", "no later than"); text != "" {
+			list = append(list, text)
+		}
+	}
+
+	for _, condition := range i.Conditions {
+		list = append(list, describeCondition(condition))
+	}
+
+	for _, rule := range i.FieldRules {
+		list = append(list, fieldRuleDescriptions[rule.Op]+" "+rule.Field)
+	}
+
+	if i.Quoted {
+		list = append(list, "encoded in a string")
+	}
+
+	if text := describeMatch(i.NameMatch); text != "" {
+		list = append(list, "name "+text)
+	}
+
+	return list
+}
+
+// describeCondition describes a conditional presence rule.
+func describeCondition(c Condition) string {
+	switch c.Op {
+	case RuleRequiredIf:
+		return "required when " + c.Field + " is " + strconv.Quote(c.Value)
+
+	case RuleRequiredWith:
+		return "required when " + c.Field + " is present"
+
+	case RuleRequiredWithout:
+		return "required when " + c.Field + " is missing"
+
+	case RuleExcludedIf:
+		return "not allowed when " + c.Field + " is " + strconv.Quote(c.Value)
+	}
+
+	return c.String()
+}
+
+// describeMatch describes a field matching mode. Exact matching is the
+// default, so it is not described.
+func describeMatch(mode string) string {
+	switch mode {
+	case "", MatchExact:
+		return ""
+
+	case MatchFold:
+		return "matched ignoring case"
+	}
+
+	return "matched using " + strconv.Quote(mode)
+}
+
+// describeEnums describes the list of enumerated values for the item.
+func (i *Item) describeEnums() string {
+	values := make([]string, len(i.Enums))
+
+	numeric := i.ItemType == TypeInt || i.ItemType == TypeFloat
+
+	for n, enum := range i.Enums {
+		if numeric {
+			values[n] = enum
+		} else {
+			values[n] = strconv.Quote(enum)
+		}
+	}
+
+	text := "one of " + strings.Join(values, ", ")
+	if i.CaseSensitive && !numeric {
+		text += " (case-sensitive)"
+	}
+
+	return text
+}
+
+// describeValues describes the minimum and maximum values for the item, using
+// the given words when only one of the limits is present.
+func describeValues(i *Item, lower, upper string) string {
+	switch {
+	case i.HasMinValue && i.HasMaxValue:
+		return "between " + formatValue(i.MinValue) + " and " + formatValue(i.MaxValue)
+
+	case i.HasMinValue:
+		return lower + " " + formatValue(i.MinValue)
+
+	case i.HasMaxValue:
+		return upper + " " + formatValue(i.MaxValue)
+	}
+
+	return ""
+}
+
+// describeRange describes a range of lengths or counts.
+func describeRange(hasMin bool, minimum int, hasMax bool, maximum int) string {
+	switch {
+	case hasMin && hasMax && minimum == maximum:
+		return "exactly " + strconv.Itoa(minimum)
+
+	case hasMin && hasMax:
+		return "between " + strconv.Itoa(minimum) + " and " + strconv.Itoa(maximum)
+
+	case hasMin:
+		return "at least " + strconv.Itoa(minimum)
+
+	case hasMax:
+		return "at most " + strconv.Itoa(maximum)
+	}
+
+	return ""
+}
+
+// resolve returns the structure an alias refers to, or the item itself if it
+// is not an alias.
+func (i *Item) resolve() *Item {
+	if i == nil || i.Alias == "" {
+		return i
+	}
+
+	if aliasItem, exists := find(aliasPrefix + i.Alias); exists && aliasItem.ItemType == TypeStruct && aliasItem.Alias == "" {
+		return aliasItem
+	}
+
+	return i
+}
+
+// structure returns the structure contained in this item, found by following
+// pointers, array elements, and map values. If there is no structure, it
+// returns nil.
+func (i *Item) structure() *Item {
+	for item := i.resolve(); item != nil; item = item.BaseType.resolve() {
+		switch item.ItemType {
+		case TypeStruct:
+			return item
+
+		case TypePointer, TypeArray, TypeMap:
+
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// isStructure reports if the item is a structure, or an alias for one.
+func (i *Item) isStructure() bool {
+	i = i.resolve()
+
+	return i != nil && i.ItemType == TypeStruct
+}
+
+// typeName returns the short name of the Go type used to define a structure,
+// or an empty string if the structure was not created from a Go type.
+func (i *Item) typeName() string {
+	name := i.Alias
+
+	if name == "" {
+		dictionaryLock.Lock()
+
+		for key, entry := range Dictionary {
+			if entry == i && strings.HasPrefix(key, aliasPrefix) {
+				name = strings.TrimPrefix(key, aliasPrefix)
+
+				break
+			}
+		}
+
+		dictionaryLock.Unlock()
+	}
+
+	// Remove the package name from the type name.
+	if n := strings.LastIndex(name, "."); n >= 0 {
+		name = name[n+1:]
+	}
+
+	return name
+}
@@ -10,40 +10,76 @@ import (
 // ValidationError represents a validation error. This includes the original error,
 // the context of the validation (e.g., the field name), the actual value, and the
 // expected values. If any of context, value, or expected values are empty, they
-// are not included in the formatted error message string.
+// are not included in the formatted error message string. The cause is an error
+// from outside the validator that explains the validation error, if any.
 type ValidationError struct {
 	err      error
 	context  string
 	value    string
 	expected string
+	cause    error
 }
 
 // Predefined validation errors.
+var ErrAllOf = NewError("all fields in group required")
+var ErrAnyOf = NewError("at least one field in group required")
 var ErrArrayLengthOutOfRange = NewError("array length out of range")
+var ErrCannotGenerate = NewError("cannot generate value")
+var ErrContainsOutOfRange = NewError("number of matching array values out of range")
+var ErrDuplicateValue = NewError("duplicate array value")
 var ErrEmptyTag = NewError("empty tag")
 var ErrEmptyTagValue = NewError("empty tag value")
 var ErrInvalidBaseTag = NewError("invalid base tag (only allowed on arrays and maps)")
+var ErrInvalidCondition = NewError("invalid condition")
 var ErrInvalidData = NewError("invalid data")
+var ErrInvalidDiscriminator = NewError("invalid discriminator value")
 var ErrInvalidDuration = NewError("invalid duration value")
 var ErrInvalidEnumeratedValue = NewError("invalid enumerated value")
 var ErrInvalidEnumType = NewError("invalid field type for enum, must be string or int")
+var ErrExcluded = NewError("field not allowed")
+var ErrFieldComparison = NewError("field comparison failed")
+var ErrFieldNameCollision = NewError("more than one key for field")
+var ErrInvalidFieldMatch = NewError("invalid field match mode")
 var ErrInvalidFieldName = NewError("invalid field name")
+var ErrInvalidGroup = NewError("invalid field group")
 var ErrInvalidInteger = NewError("invalid integer value")
 var ErrInvalidKeyword = NewError("invalid keyword")
 var ErrInvalidListTag = NewError("invalid list tag for item type")
 var ErrInvalidName = NewError("invalid name")
+var ErrInvalidOperand = NewError("invalid operand")
+var ErrInvalidPattern = NewError("invalid pattern")
+var ErrInvalidRule = NewError("invalid rule")
 var ErrInvalidTagName = NewError("invalid tag name")
 var ErrInvalidValidator = NewError("invalid JSON instance of validator")
+var ErrManyVariants = NewError("value matches more than one variant")
+var ErrMapLengthOutOfRange = NewError("map length out of range")
 var ErrMaxDepthExceeded = NewError("maximum validation depth exceeded")
 var ErrMissingEnumValue = NewError("missing enum values")
 var ErrNameAlreadyExists = NewError("name already exists")
 var ErrNilValidator = NewError("nil validator")
+var ErrNoVariant = NewError("value does not match any variant")
+var ErrNoneOf = NewError("fields in group not allowed")
+var ErrNotMultiple = NewError("value is not a multiple of the step")
 var ErrNotAMap = NewError("keyword only valid with map type")
+var ErrNotAnArray = NewError("keyword only valid with array type")
+var ErrNotANumber = NewError("keyword only valid with numeric type")
+var ErrNotAStruct = NewError("keyword only valid with struct type")
+var ErrNotAUnion = NewError("keyword only valid with union type")
+
+// ErrNullNotAllowed has the same text as ErrInvalidData, so the message for a
+// null value is the same as it has always been, but errors.Is() can tell the
+// two errors apart.
+var ErrNullNotAllowed = NewError("invalid data")
+
+var ErrOneOf = NewError("exactly one field in group required")
+var ErrPatternMismatch = NewError("value does not match pattern")
 var ErrRequired = NewError("required field missing")
+var ErrRuleFailed = NewError("rule failed")
 var ErrSyntaxError = NewError("syntax error")
 var ErrUndefinedStructure = NewError("undefined structure")
 var ErrUnimplemented = NewError("unimplemented type")
 var ErrUnsupportedType = NewError("unsupported type")
+var ErrValidationFailed = NewError("validation failed")
 var ErrValueOutOfRange = NewError("value out of range")
 var ErrValueLengthOutOfRange = NewError("value length out of range")
 
@@ -77,15 +113,14 @@ func (e *ValidationError) Value(value any) *ValidationError {
 		return nil
 	}
 
-	if value == nil {
-		e.value = ""
+	e2 := e.copy()
 
-		return e
+	if value == nil {
+		e2.value = ""
+	} else {
+		e2.value = fmt.Sprintf("%v", value)
 	}
 
-	e2 := e.copy()
-	e2.value = fmt.Sprintf("%v", value)
-
 	return e2
 }
 
@@ -130,6 +165,40 @@ func (e *ValidationError) Expected(expected ...any) *ValidationError {
 	return e2
 }
 
+// Cause adds the error that caused the validation error, such as an error
+// returned by a type's UnmarshalJSON() method. This returns a copy of the
+// validation error with the cause as a new validation error. The cause can
+// be retrieved using errors.Unwrap().
+func (e *ValidationError) Cause(err error) *ValidationError {
+	if e == nil {
+		return nil
+	}
+
+	e2 := e.copy()
+	e2.cause = err
+
+	return e2
+}
+
+// Unwrap returns the error that caused the validation error, or nil if
+// there is no cause.
+func (e *ValidationError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+
+	return e.cause
+}
+
+// Is reports if the target is the same kind of validation error, ignoring the
+// context, value, and expected values. This allows errors.Is() to be used to
+// test for one of the predefined errors, such as ErrRequired.
+func (e *ValidationError) Is(target error) bool {
+	t, ok := target.(*ValidationError)
+
+	return ok && e != nil && t != nil && e.err == t.err
+}
+
 // Make a copy of the validation error. This allows the caller to use
 // a predefined error variable, and add unique context, values, etc. to
 // the message without modifying the original error.
@@ -139,9 +208,11 @@ func (e *ValidationError) copy() *ValidationError {
 	}
 
 	return &ValidationError{
-		err:     e.err,
-		context: e.context,
-		value:   e.value,
+		err:      e.err,
+		context:  e.context,
+		value:    e.value,
+		expected: e.expected,
+		cause:    e.cause,
 	}
 }
 
@@ -168,5 +239,9 @@ func (e *ValidationError) Error() string {
 		result += fmt.Sprintf(", expected %s", e.expected)
 	}
 
+	if e.cause != nil {
+		result += fmt.Sprintf(" (%s)", e.cause.Error())
+	}
+
 	return result
 }
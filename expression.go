@@ -0,0 +1,1083 @@
+package validator
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// The maximum nesting of parentheses, function calls, and unary operators in
+// a rule expression.
+const maxExpressionDepth = 64
+
+// The functions that can be called in a rule expression, with the minimum and
+// maximum number of arguments for each. A maximum of -1 allows any number.
+var expressionFunctions = map[string][2]int{
+	"abs":      {1, 1},
+	"duration": {1, 1},
+	"len":      {1, 1},
+	"max":      {1, -1},
+	"min":      {1, -1},
+	"now":      {0, 0},
+	"sum":      {1, 1},
+	"time":     {1, 1},
+}
+
+// The operators made of two characters. The scanner returns each character as a
+// separate token, so adjacent tokens are joined to form these operators.
+var twoCharOperators = map[string]bool{
+	"==": true,
+	"!=": true,
+	"<=": true,
+	">=": true,
+	"&&": true,
+	"||": true,
+}
+
+// expression is a compiled rule expression. The text is the source of the
+// expression, and the root is the node that is evaluated.
+type expression struct {
+	text string
+	root node
+}
+
+// node is an element of a compiled rule expression.
+type node interface {
+	eval(ctx *evalContext) (any, error)
+}
+
+// evalContext holds the values used while evaluating an expression. The time
+// is read once, so every call to now() in an expression returns the same time.
+type evalContext struct {
+	object map[string]any
+	now    time.Time
+}
+
+type (
+	// A literal number, string, boolean, or null value.
+	literalNode struct {
+		value any
+	}
+
+	// A field of the object being validated.
+	fieldNode struct {
+		name string
+	}
+
+	// A field of an object. If the target is an array, the field is read
+	// from each element of the array.
+	memberNode struct {
+		target node
+		name   string
+	}
+
+	// An element of an array. If the index is nil, this is the [*] wildcard,
+	// which selects all of the elements.
+	indexNode struct {
+		target node
+		index  node
+	}
+
+	// A call to one of the expression functions.
+	callNode struct {
+		name string
+		args []node
+	}
+
+	// A unary operator, "!" or "-".
+	unaryNode struct {
+		op      string
+		operand node
+	}
+
+	// A binary operator.
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+)
+
+// exprParser compiles the tokens of a rule expression.
+type exprParser struct {
+	t     *tokenizer
+	depth int
+}
+
+// compileExpression compiles the text of a rule expression. Errors report the
+// position in the text where the problem was found.
+func compileExpression(text string) (*expression, error) {
+	t, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{t: t}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if next := t.next(); next != "" {
+		return nil, ErrSyntaxError.Context(t.pos()).Value(next).Expected("operator")
+	}
+
+	return &expression{text: text, root: root}, nil
+}
+
+// compileExpressions compiles a list of rule expressions.
+func compileExpressions(list []string) ([]*expression, error) {
+	result := make([]*expression, 0, len(list))
+
+	for _, text := range list {
+		e, err := compileExpression(text)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+// AddRule adds a rule expression to a structure validator. The expression must
+// be true for every JSON object, such as "total == sum(.lines[*].amount)". For a
+// pointer, array, or map validator, the rule is added to the structure it
+// contains. An error is returned if the expression cannot be compiled.
+func (i *Item) AddRule(text string) error {
+	if i == nil {
+		return ErrNilValidator.Context(text)
+	}
+
+	target := i.matchTarget()
+	if target == nil {
+		return ErrInvalidRule.Context(text).Value(i.ItemType.String())
+	}
+
+	e, err := compileExpression(text)
+	if err != nil {
+		return err
+	}
+
+	// Make sure the existing expressions are compiled, so the compiled list
+	// matches the list of expressions.
+	list, err := target.expressions()
+	if err != nil {
+		return err
+	}
+
+	target.Expressions = append(target.Expressions, text)
+	target.compiled = append(list, e)
+
+	return nil
+}
+
+// compileRules compiles the rule expressions of the structure, and stores the
+// compiled expressions so they are not compiled each time a value is checked.
+func (i *Item) compileRules() error {
+	list, err := i.expressions()
+	if err != nil {
+		return err
+	}
+
+	if err := i.verifyRules(list); err != nil {
+		return err
+	}
+
+	i.compiled = list
+
+	return nil
+}
+
+// verifyRules reports an error if a rule expression names a field that does
+// not exist in the structure. A structure that accepts keys that are not fields
+// can name those keys, so its expressions are not checked.
+func (i *Item) verifyRules(list []*expression) error {
+	if i.AllowForeignKey || i.AdditionalProperties != nil || len(i.PatternProperties) > 0 {
+		return nil
+	}
+
+	for _, e := range list {
+		for _, name := range fieldNames(e.root, nil) {
+			if i.exactField(name) == nil {
+				return ErrInvalidFieldName.Context("rule").Value(name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldNames returns the names of the fields of the object that are read by an
+// expression node, added to the list.
+func fieldNames(n node, list []string) []string {
+	switch actual := n.(type) {
+	case *fieldNode:
+		list = append(list, actual.name)
+
+	case *memberNode:
+		list = fieldNames(actual.target, list)
+
+	case *indexNode:
+		list = fieldNames(actual.target, list)
+		if actual.index != nil {
+			list = fieldNames(actual.index, list)
+		}
+
+	case *callNode:
+		for _, arg := range actual.args {
+			list = fieldNames(arg, list)
+		}
+
+	case *unaryNode:
+		list = fieldNames(actual.operand, list)
+
+	case *binaryNode:
+		list = fieldNames(actual.left, list)
+		list = fieldNames(actual.right, list)
+	}
+
+	return list
+}
+
+// expressions returns the compiled rule expressions of the structure. If they
+// have not been compiled, such as when the rules were set directly in the
+// Expressions list, they are compiled but not stored, so a validator can safely
+// be used by more than one goroutine.
+func (i *Item) expressions() ([]*expression, error) {
+	if len(i.compiled) == len(i.Expressions) {
+		return i.compiled, nil
+	}
+
+	return compileExpressions(i.Expressions)
+}
+
+// validateExpressions checks that each rule expression of the structure is true
+// for the JSON object, using the key found for each field.
+func (i *Item) validateExpressions(m map[string]any, keys map[*Item]string) error {
+	list, err := i.expressions()
+	if err != nil || len(list) == 0 {
+		return err
+	}
+
+	object := i.expressionObject(m, keys)
+
+	for _, e := range list {
+		ok, err := e.evaluate(object)
+		if err != nil {
+			return ErrRuleFailed.Context(i.Name).Value(e.text).Cause(err)
+		}
+
+		if !ok {
+			return ErrRuleFailed.Context(i.Name).Value(e.text)
+		}
+	}
+
+	return nil
+}
+
+// expressionObject returns the values of the JSON object as seen by the rule
+// expressions. Each field is stored using the field name rather than the key
+// that matched it, the value of a quoted field is decoded, numbers are
+// converted to exact rational values, and time and duration values are
+// converted so they can be compared. Foreign keys are included using their keys.
+func (i *Item) expressionObject(m map[string]any, keys map[*Item]string) map[string]any {
+	result := make(map[string]any, len(m))
+	used := make(map[string]bool, len(keys))
+
+	for _, field := range i.Fields {
+		key, found := keys[field]
+		if !found {
+			continue
+		}
+
+		used[key] = true
+		value := m[key]
+
+		if field.Quoted && value != nil {
+			if decoded, err := field.quotedValue(value); err == nil {
+				value = decoded
+			}
+		}
+
+		base := field
+		for base.ItemType == TypePointer && base.BaseType != nil {
+			base = base.BaseType
+		}
+
+		switch base.ItemType {
+		case TypeTime:
+			if t, err := getTimeValue(value); err == nil {
+				value = t
+			}
+
+		case TypeDuration:
+			if d, err := getDurationValue(value); err == nil {
+				value = d
+			}
+		}
+
+		result[field.Name] = normalizeNumbers(value, exprNumber)
+	}
+
+	for key, value := range m {
+		if _, found := result[key]; !found && !used[key] {
+			result[key] = normalizeNumbers(value, exprNumber)
+		}
+	}
+
+	return result
+}
+
+// operator returns the operator at the current position, joining adjacent
+// characters that form a two-character operator, and the number of tokens it
+// uses. The tokens are not consumed.
+func (p *exprParser) operator() (string, int) {
+	first := p.t.peek(0)
+	if second := p.t.peek(1); second != "" && twoCharOperators[first+second] {
+		p.t.position++
+		adjacent := p.t.adjacent()
+		p.t.position--
+
+		if adjacent {
+			return first + second, 2
+		}
+	}
+
+	return first, 1
+}
+
+// enter increases the nesting depth, and reports an error if it is too deep.
+func (p *exprParser) enter() error {
+	p.depth++
+	if p.depth > maxExpressionDepth {
+		return ErrSyntaxError.Context(p.t.pos()).Value("expression is nested too deeply")
+	}
+
+	return nil
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (node, error) {
+	left, err := p.parseAdd()
+	if err != nil {
+		return nil, err
+	}
+
+	op, count := p.operator()
+
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.t.position += count
+
+		right, err := p.parseAdd()
+		if err != nil {
+			return nil, err
+		}
+
+		return &binaryNode{op: op, left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) parseAdd() (node, error) {
+	return p.parseBinary(p.parseMultiply, "+", "-")
+}
+
+func (p *exprParser) parseMultiply() (node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+// parseBinary parses a sequence of operands separated by any of the given
+// left-associative operators.
+func (p *exprParser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, count := p.operator()
+
+		found := false
+
+		for _, candidate := range ops {
+			if op == candidate {
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return left, nil
+		}
+
+		p.t.position += count
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	if op, _ := p.operator(); op == "!" || op == "-" {
+		p.t.next()
+
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		p.depth--
+
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+// parsePostfix parses a primary value followed by any number of field names
+// and array indexes, such as ".lines[0].amount".
+func (p *exprParser) parsePostfix() (node, error) {
+	result, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.t.peek(0) {
+		case ".":
+			p.t.next()
+
+			name := p.t.next()
+			if !isIdentifier(name) {
+				return nil, ErrSyntaxError.Context(p.t.pos()).Value(name).Expected("field name")
+			}
+
+			result = &memberNode{target: result, name: name}
+
+		case "[":
+			p.t.next()
+
+			if p.t.peek(0) == "*" {
+				p.t.next()
+
+				result = &indexNode{target: result}
+			} else {
+				index, err := p.parseNested()
+				if err != nil {
+					return nil, err
+				}
+
+				result = &indexNode{target: result, index: index}
+			}
+
+			if next := p.t.next(); next != "]" {
+				return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected("]")
+			}
+
+		default:
+			return result, nil
+		}
+	}
+}
+
+// parseNested parses an expression inside parentheses, brackets, or the
+// arguments of a function.
+func (p *exprParser) parseNested() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+
+	result, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.depth--
+
+	return result, nil
+}
+
+func (p *exprParser) parsePrimary() (node, error) {
+	next := p.t.next()
+
+	switch {
+	case next == "":
+		return nil, ErrSyntaxError.Context(p.t.pos()).Value("end of expression").Expected("value")
+
+	case next == "(":
+		result, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+
+		if next := p.t.next(); next != ")" {
+			return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected(")")
+		}
+
+		return result, nil
+
+	case next == ".":
+		name := p.t.next()
+		if !isIdentifier(name) {
+			return nil, ErrSyntaxError.Context(p.t.pos()).Value(name).Expected("field name")
+		}
+
+		return &fieldNode{name: name}, nil
+
+	case next == "true" || next == "false":
+		return &literalNode{value: next == "true"}, nil
+
+	case next == "null":
+		return &literalNode{}, nil
+
+	case strings.HasPrefix(next, "\"") || strings.HasPrefix(next, "`"):
+		text, err := strconv.Unquote(next)
+		if err != nil {
+			return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected("string")
+		}
+
+		return &literalNode{value: text}, nil
+
+	case unicode.IsDigit(rune(next[0])) || next[0] == '.':
+		n, ok := parseNumber(next)
+		if !ok {
+			return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected("number")
+		}
+
+		return &literalNode{value: n}, nil
+
+	case isIdentifier(next):
+		if p.t.peek(0) == "(" {
+			return p.parseCall(next)
+		}
+
+		return &fieldNode{name: next}, nil
+	}
+
+	return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected("value")
+}
+
+// parseCall parses the arguments of a call to an expression function. The
+// name of the function has already been read.
+func (p *exprParser) parseCall(name string) (node, error) {
+	limits, found := expressionFunctions[name]
+	if !found {
+		return nil, ErrSyntaxError.Context(p.t.pos()).Value(name).Expected("function name")
+	}
+
+	p.t.next()
+
+	call := &callNode{name: name}
+
+	for p.t.peek(0) != ")" {
+		if len(call.args) > 0 {
+			if next := p.t.next(); next != "," {
+				return nil, ErrSyntaxError.Context(p.t.pos()).Value(next).Expected(",", ")")
+			}
+		}
+
+		arg, err := p.parseNested()
+		if err != nil {
+			return nil, err
+		}
+
+		call.args = append(call.args, arg)
+	}
+
+	p.t.next()
+
+	if len(call.args) < limits[0] || (limits[1] >= 0 && len(call.args) > limits[1]) {
+		expected := strconv.Itoa(limits[0]) + " argument"
+		if limits[0] != 1 {
+			expected += "s"
+		}
+
+		if limits[1] < 0 {
+			expected = "at least " + expected
+		}
+
+		return nil, ErrSyntaxError.Context(p.t.pos()).Value(name).Expected(expected)
+	}
+
+	return call, nil
+}
+
+// isIdentifier reports if the token is a name.
+func isIdentifier(token string) bool {
+	return identifierPattern.MatchString(token)
+}
+
+// evaluate evaluates the expression for the JSON object, and reports if the
+// result is true. An error is returned if the expression cannot be evaluated,
+// or if the result is not a boolean value.
+func (e *expression) evaluate(object map[string]any) (bool, error) {
+	result, err := e.root.eval(&evalContext{object: object, now: time.Now()})
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := result.(bool)
+	if !ok {
+		return false, invalidOperand("rule", result)
+	}
+
+	return b, nil
+}
+
+func (n *literalNode) eval(ctx *evalContext) (any, error) {
+	return n.value, nil
+}
+
+func (n *fieldNode) eval(ctx *evalContext) (any, error) {
+	return ctx.object[n.name], nil
+}
+
+func (n *memberNode) eval(ctx *evalContext) (any, error) {
+	target, err := n.target.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return member(target, n.name)
+}
+
+// member returns the named field of an object. A field of null is null, and a
+// field of an array is the list of that field of each element.
+func member(v any, name string) (any, error) {
+	switch actual := v.(type) {
+	case nil:
+		return nil, nil
+
+	case map[string]any:
+		return actual[name], nil
+
+	case []any:
+		result := make([]any, len(actual))
+
+		for n, element := range actual {
+			value, err := member(element, name)
+			if err != nil {
+				return nil, err
+			}
+
+			result[n] = value
+		}
+
+		return result, nil
+	}
+
+	return nil, invalidOperand("."+name, v)
+}
+
+func (n *indexNode) eval(ctx *evalContext) (any, error) {
+	target, err := n.target.eval(ctx)
+	if err != nil || target == nil {
+		return nil, err
+	}
+
+	array, ok := target.([]any)
+	if !ok {
+		return nil, invalidOperand("[]", target)
+	}
+
+	if n.index == nil {
+		return array, nil
+	}
+
+	index, err := n.index.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	position, ok := index.(*big.Rat)
+	if !ok || !position.IsInt() {
+		return nil, invalidOperand("[]", index)
+	}
+
+	// An element that does not exist is null, like a missing field.
+	if position.Sign() < 0 || position.Cmp(new(big.Rat).SetInt64(int64(len(array)))) >= 0 {
+		return nil, nil
+	}
+
+	return array[position.Num().Int64()], nil
+}
+
+func (n *unaryNode) eval(ctx *evalContext) (any, error) {
+	v, err := n.operand.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch actual := v.(type) {
+	case bool:
+		if n.op == "!" {
+			return !actual, nil
+		}
+
+	case *big.Rat:
+		if n.op == "-" {
+			return new(big.Rat).Neg(actual), nil
+		}
+
+	case time.Duration:
+		if n.op == "-" {
+			return -actual, nil
+		}
+	}
+
+	return nil, invalidOperand(n.op, v)
+}
+
+func (n *binaryNode) eval(ctx *evalContext) (any, error) {
+	left, err := n.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The logical operators only evaluate the right operand if needed.
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, invalidOperand(n.op, left)
+		}
+
+		if l == (n.op == "||") {
+			return l, nil
+		}
+
+		right, err := n.right.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		r, ok := right.(bool)
+		if !ok {
+			return nil, invalidOperand(n.op, right)
+		}
+
+		return r, nil
+	}
+
+	right, err := n.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// A time or duration can be compared with, or added to, a string that
+	// contains a time or duration.
+	left, right = coerce(left, right), coerce(right, left)
+
+	switch n.op {
+	case "==", "!=":
+		equal := reflect.DeepEqual(left, right)
+		if result, ok := compareExpression(left, right); ok {
+			equal = result == 0
+		}
+
+		return equal == (n.op == "=="), nil
+
+	case "<", "<=", ">", ">=":
+		result, ok := compareExpression(left, right)
+		if !ok {
+			return nil, invalidOperand(n.op, left)
+		}
+
+		switch n.op {
+		case "<":
+			return result < 0, nil
+		case "<=":
+			return result <= 0, nil
+		case ">":
+			return result > 0, nil
+		default:
+			return result >= 0, nil
+		}
+	}
+
+	return arithmetic(n.op, left, right)
+}
+
+// coerce converts a string to a time or duration if the other operand is a
+// time or duration.
+func coerce(v, other any) any {
+	text, ok := v.(string)
+	if !ok {
+		return v
+	}
+
+	switch other.(type) {
+	case time.Time:
+		if t, err := getTimeValue(text); err == nil {
+			return t
+		}
+
+	case time.Duration:
+		if d, err := getDurationValue(text); err == nil {
+			return d
+		}
+	}
+
+	return v
+}
+
+// compareExpression compares two values of the same ordered type. It returns
+// false if the values cannot be compared.
+func compareExpression(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case *big.Rat:
+		if y, ok := b.(*big.Rat); ok {
+			return x.Cmp(y), true
+		}
+
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+
+	case time.Duration:
+		if y, ok := b.(time.Duration); ok {
+			return big.NewInt(int64(x)).Cmp(big.NewInt(int64(y))), true
+		}
+	}
+
+	return 0, false
+}
+
+// arithmetic applies an arithmetic operator to two values. Numbers support all
+// of the operators, and the results are exact. Strings can be joined with "+",
+// and times and durations can be added and subtracted.
+func arithmetic(op string, left, right any) (any, error) {
+	switch x := left.(type) {
+	case *big.Rat:
+		y, ok := right.(*big.Rat)
+		if !ok {
+			break
+		}
+
+		switch op {
+		case "+":
+			return new(big.Rat).Add(x, y), nil
+		case "-":
+			return new(big.Rat).Sub(x, y), nil
+		case "*":
+			return new(big.Rat).Mul(x, y), nil
+		case "/", "%":
+			if y.Sign() == 0 {
+				return nil, invalidOperand(op, right)
+			}
+
+			quotient := new(big.Rat).Quo(x, y)
+			if op == "/" {
+				return quotient, nil
+			}
+
+			// Like math.Mod, the remainder has the sign of the left operand.
+			whole := new(big.Int).Quo(quotient.Num(), quotient.Denom())
+
+			return new(big.Rat).Sub(x, new(big.Rat).Mul(y, new(big.Rat).SetInt(whole))), nil
+		}
+
+	case string:
+		if y, ok := right.(string); ok && op == "+" {
+			return x + y, nil
+		}
+
+	case time.Time:
+		switch y := right.(type) {
+		case time.Duration:
+			if op == "+" {
+				return x.Add(y), nil
+			}
+
+			if op == "-" {
+				return x.Add(-y), nil
+			}
+
+		case time.Time:
+			if op == "-" {
+				return x.Sub(y), nil
+			}
+		}
+
+	case time.Duration:
+		if y, ok := right.(time.Duration); ok && (op == "+" || op == "-") {
+			if op == "-" {
+				y = -y
+			}
+
+			return x + y, nil
+		}
+	}
+
+	return nil, invalidOperand(op, left)
+}
+
+func (n *callNode) eval(ctx *evalContext) (any, error) {
+	args := make([]any, len(n.args))
+
+	for i, arg := range n.args {
+		v, err := arg.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = v
+	}
+
+	switch n.name {
+	case "now":
+		return ctx.now, nil
+
+	case "len":
+		switch actual := args[0].(type) {
+		case string:
+			return new(big.Rat).SetInt64(int64(len(actual))), nil
+		case []any:
+			return new(big.Rat).SetInt64(int64(len(actual))), nil
+		case map[string]any:
+			return new(big.Rat).SetInt64(int64(len(actual))), nil
+		}
+
+	case "abs":
+		if n, ok := args[0].(*big.Rat); ok {
+			return new(big.Rat).Abs(n), nil
+		}
+
+	case "time":
+		if t, err := getTimeValue(args[0]); err == nil {
+			return t, nil
+		}
+
+	case "duration":
+		if d, err := getDurationValue(args[0]); err == nil {
+			return d, nil
+		}
+
+	case "sum", "min", "max":
+		// These functions accept a list of numbers, or a single array of
+		// numbers. Null values in the list are ignored.
+		if list, ok := args[0].([]any); ok && len(args) == 1 {
+			args = list
+		}
+
+		return aggregate(n.name, args)
+	}
+
+	return nil, invalidOperand(n.name, args[0])
+}
+
+// aggregate returns the sum, minimum, or maximum of a list of numbers. The sum
+// of an empty list is zero, and the minimum or maximum of an empty list is null.
+func aggregate(name string, list []any) (any, error) {
+	var result any
+
+	total := new(big.Rat)
+
+	for _, v := range list {
+		if v == nil {
+			continue
+		}
+
+		n, ok := v.(*big.Rat)
+		if !ok {
+			return nil, invalidOperand(name, v)
+		}
+
+		total.Add(total, n)
+
+		switch current, _ := result.(*big.Rat); {
+		case result == nil:
+			result = n
+		case name == "min" && n.Cmp(current) < 0, name == "max" && n.Cmp(current) > 0:
+			result = n
+		}
+	}
+
+	if name == "sum" {
+		return total, nil
+	}
+
+	return result, nil
+}
+
+// exprNumber converts a JSON number to the exact rational value used by rule
+// expressions, so large integers and decimal fractions are not rounded. A number
+// that cannot be read exactly, such as one with a very large exponent, is kept
+// as it is, and is not a valid operand.
+func exprNumber(n json.Number) any {
+	if r, ok := parseNumber(string(n)); ok {
+		return r
+	}
+
+	return n
+}
+
+// decimalNumber returns the JSON number for a value computed by a rule
+// expression. It returns false if the value has no exact decimal form, such
+// as one third.
+func decimalNumber(r *big.Rat) (json.Number, bool) {
+	if r.IsInt() {
+		return json.Number(r.Num().String()), true
+	}
+
+	// A fraction has a decimal form if its denominator is a product of twos
+	// and fives.
+	d := new(big.Int).Set(r.Denom())
+	for _, factor := range []*big.Int{big.NewInt(2), big.NewInt(5)} {
+		q, m := new(big.Int), new(big.Int)
+
+		for q.QuoRem(d, factor, m); m.Sign() == 0; q.QuoRem(d, factor, m) {
+			d.Set(q)
+		}
+	}
+
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return "", false
+	}
+
+	return json.Number(numberText(json.Number(r.FloatString(r.Denom().BitLen())))), true
+}
+
+// invalidOperand returns the error for an operand that is not valid for an
+// operator or function. Numbers are reported as decimal text.
+func invalidOperand(context string, v any) error {
+	if r, ok := v.(*big.Rat); ok {
+		if n, ok := decimalNumber(r); ok {
+			v = n
+		} else {
+			f, _ := r.Float64()
+			v = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+
+	return ErrInvalidOperand.Context(context).Value(v)
+}
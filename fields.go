@@ -0,0 +1,258 @@
+package validator
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// structField describes a field of a structure the way encoding/json sees it.
+// The name is the JSON key used for the field, and the index is the field's
+// location in the structure, as used by reflect.Type.FieldByIndex(). If the
+// field was promoted from embedded structures, the rules are the validate
+// tags of the embedded fields, from the outermost to the innermost.
+type structField struct {
+	name   string
+	tagged bool
+	quoted bool
+	index  []int
+	typ    reflect.Type
+	field  reflect.StructField
+	rules  []string
+}
+
+// typeFields returns the fields of a structure type that encoding/json would
+// marshal and unmarshal, in field order. Fields tagged with json:"-" and
+// unexported fields are skipped. The fields of embedded structures without a
+// json tag name are promoted to the containing structure.
+//
+// When more than one field has the same JSON name, the field that is least
+// deeply nested is used. If there is more than one at that depth, the field
+// with a json tag name is used. If there is no single field that wins, all of
+// the fields with that name are ignored, which is what encoding/json does.
+func typeFields(t reflect.Type) []structField {
+	fields := []structField{}
+
+	// The embedded structures to search at the current and next depth, and
+	// the number of times each type was found at that depth.
+	current := []structField{}
+	next := []structField{{typ: t}}
+	count := map[reflect.Type]int{}
+	nextCount := map[reflect.Type]int{}
+
+	// Types already searched at a shallower depth.
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+
+			visited[f.typ] = true
+
+			for n := 0; n < f.typ.NumField(); n++ {
+				field := f.typ.Field(n)
+
+				// Unexported embedded structures are still searched for
+				// exported fields, but other unexported fields are ignored.
+				if field.Anonymous {
+					embedded := field.Type
+					if embedded.Kind() == reflect.Pointer {
+						embedded = embedded.Elem()
+					}
+
+					if !field.IsExported() && embedded.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+
+				tag := field.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				name, options, _ := strings.Cut(tag, ",")
+				if !isValidTag(name) {
+					name = ""
+				}
+
+				index := make([]int, len(f.index)+1)
+				copy(index, f.index)
+				index[len(f.index)] = n
+
+				fieldType := field.Type
+				if fieldType.Name() == "" && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+
+				// An embedded structure without a name is searched for
+				// fields at the next depth.
+				if name == "" && field.Anonymous && fieldType.Kind() == reflect.Struct {
+					nextCount[fieldType]++
+					if nextCount[fieldType] == 1 {
+						rules := f.rules
+						if tag := field.Tag.Get(validateTagName); strings.TrimSpace(tag) != "" {
+							rules = append(append([]string{}, f.rules...), tag)
+						}
+
+						next = append(next, structField{
+							name:  fieldType.Name(),
+							index: index,
+							typ:   fieldType,
+							rules: rules,
+						})
+					}
+
+					continue
+				}
+
+				sf := structField{
+					name:   name,
+					tagged: name != "",
+					quoted: hasOption(options, "string") && isScalar(fieldType),
+					index:  index,
+					typ:    fieldType,
+					field:  field,
+					rules:  f.rules,
+				}
+
+				if !sf.tagged {
+					sf.name = field.Name
+				}
+
+				fields = append(fields, sf)
+
+				// If the structure containing this field was found more than
+				// once at this depth, add the field again so it is seen as a
+				// duplicate and ignored.
+				if count[f.typ] > 1 {
+					fields = append(fields, sf)
+				}
+			}
+		}
+	}
+
+	return dominantFields(fields)
+}
+
+// isScalar reports if the type is one that the json ",string" option applies
+// to. The option is ignored for any other type.
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+
+	return false
+}
+
+// dominantFields removes the fields that are hidden by other fields with the
+// same name. The remaining fields are returned in field index order.
+func dominantFields(fields []structField) []structField {
+	sort.SliceStable(fields, func(a, b int) bool {
+		if fields[a].name != fields[b].name {
+			return fields[a].name < fields[b].name
+		}
+
+		if len(fields[a].index) != len(fields[b].index) {
+			return len(fields[a].index) < len(fields[b].index)
+		}
+
+		return fields[a].tagged && !fields[b].tagged
+	})
+
+	result := fields[:0]
+
+	for start := 0; start < len(fields); {
+		end := start + 1
+		for end < len(fields) && fields[end].name == fields[start].name {
+			end++
+		}
+
+		if field, ok := dominantField(fields[start:end]); ok {
+			result = append(result, field)
+		}
+
+		start = end
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return lessIndex(result[a].index, result[b].index)
+	})
+
+	return result
+}
+
+// dominantField returns the field that is used from a list of fields with the
+// same name, sorted by depth and then by whether they are tagged. If there is
+// no single field at the shallowest depth that wins, it returns false.
+func dominantField(fields []structField) (structField, bool) {
+	if len(fields) > 1 &&
+		len(fields[0].index) == len(fields[1].index) &&
+		fields[0].tagged == fields[1].tagged {
+		return structField{}, false
+	}
+
+	return fields[0], true
+}
+
+// lessIndex reports if the field index a comes before the field index b.
+func lessIndex(a, b []int) bool {
+	for n, x := range a {
+		if n >= len(b) {
+			return false
+		}
+
+		if x != b[n] {
+			return x < b[n]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// hasOption reports if the comma-separated list of json tag options contains
+// the given option.
+func hasOption(options, option string) bool {
+	for options != "" {
+		var name string
+
+		name, options, _ = strings.Cut(options, ",")
+		if name == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isValidTag reports if a json tag name is valid. encoding/json uses the name
+// of the field instead of an invalid tag name.
+func isValidTag(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for _, c := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote characters are reserved, but otherwise
+			// any punctuation characters are allowed in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// The number of generated documents added to the corpus by the Fuzz() function.
+const defaultFuzzSeeds = 20
+
+// Fuzz runs a native Go fuzz test for a validator. The corpus is seeded with
+// documents created by a Generator for the validator, and each fuzz input is
+// checked using CheckProperties(). Call this from a fuzz test function, as in:
+//
+//	func FuzzEmployees(f *testing.F) {
+//	    v, _ := validator.New(&Employees{})
+//	    validator.Fuzz(f, v)
+//	}
+func Fuzz(f *testing.F, item *Item) {
+	if err := SeedCorpus(f, item, defaultFuzzSeeds); err != nil {
+		f.Fatalf("unable to seed fuzz corpus: %v", err)
+	}
+
+	// The generator is created once, and is reset to the seed of each input.
+	g := NewGenerator(0)
+
+	f.Fuzz(func(t *testing.T, text string, seed int64) {
+		g.reset(seed)
+		checkProperties(t, g, item, text)
+	})
+}
+
+// SeedCorpus adds documents created by a Generator for the validator to the
+// corpus of a fuzz test. Each seed value from zero up to the count is used to
+// create a valid document and the invalid documents derived from it. The fuzz
+// function must accept a string and an int64 seed value.
+func SeedCorpus(f *testing.F, item *Item, count int) error {
+	for seed := int64(0); seed < int64(count); seed++ {
+		g := NewGenerator(seed)
+
+		text, err := g.Valid(item)
+		if err != nil {
+			return err
+		}
+
+		f.Add(text, seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			return err
+		}
+
+		for _, doc := range docs {
+			f.Add(doc.Text, seed)
+		}
+	}
+
+	return nil
+}
+
+// CheckProperties verifies the properties that must hold for any input to
+// a validator. Validating the text must not panic, whether or not the text
+// is valid JSON. The valid document created by a Generator using the seed
+// must be accepted by the validator, and each of the invalid documents it
+// creates must be rejected. If the rules of the validator cannot be
+// satisfied, the test is skipped with the reason once the first property
+// is checked, and any other error from the generator fails the test.
+func CheckProperties(t testing.TB, item *Item, text string, seed int64) {
+	t.Helper()
+
+	checkProperties(t, NewGenerator(seed), item, text)
+}
+
+// checkProperties verifies the properties of CheckProperties() using a
+// generator that has already been seeded.
+func checkProperties(t testing.TB, g *Generator, item *Item, text string) {
+	t.Helper()
+
+	if err := safeValidate(item, text); err != nil {
+		if _, ok := err.(*panicError); ok {
+			t.Fatalf("Validate() panicked for %q: %v", text, err)
+		}
+	}
+
+	valid, err := g.Valid(item)
+	if err != nil {
+		generatorFailed(t, "Valid()", err)
+
+		return
+	}
+
+	if err := safeValidate(item, valid); err != nil {
+		t.Fatalf("Validate() rejected generated document %s: %v", valid, err)
+	}
+
+	docs, err := g.Invalid(item)
+	if err != nil {
+		generatorFailed(t, "Invalid()", err)
+
+		return
+	}
+
+	for _, doc := range docs {
+		err := safeValidate(item, doc.Text)
+		if err == nil {
+			t.Fatalf("Validate() accepted generated document for rule %s at %q: %s", doc.Rule, doc.Path, doc.Text)
+		}
+
+		if _, ok := err.(*panicError); ok {
+			t.Fatalf("Validate() panicked for %s: %v", doc.Text, err)
+		}
+	}
+}
+
+// generatorFailed reports an error from a Generator, which ends the test. A
+// validator whose rules cannot be satisfied skips the test, and any other
+// error fails it.
+func generatorFailed(t testing.TB, function string, err error) {
+	t.Helper()
+
+	if errors.Is(err, ErrCannotGenerate) {
+		t.Skipf("%s cannot generate documents: %v", function, err)
+	}
+
+	t.Fatalf("%s unexpected error: %v", function, err)
+}
+
+// panicError records a panic that occurred during validation.
+type panicError struct {
+	value any
+}
+
+func (p *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.value)
+}
+
+// safeValidate validates the text, converting any panic into an error.
+func safeValidate(item *Item, text string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+
+	return item.Validate(text)
+}
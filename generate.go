@@ -0,0 +1,1975 @@
+package validator
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The default nesting depth after which a generator omits optional fields and
+// creates the smallest permitted arrays and maps.
+const defaultGeneratorDepth = 4
+
+// The number of values either side of zero (or the nearest limit) that a
+// generator chooses numeric values from, when the range is not limited.
+const generatorSpread = 100
+
+// The number of attempts made to create a structure that is accepted by the
+// structure type's own validation methods.
+const generatorAttempts = 20
+
+// The time used as the center of the range of generated times when the
+// validator does not specify a minimum or maximum time.
+var generatorBaseTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator creates JSON documents from a validator. The documents can be
+// valid examples of the JSON described by the validator, or documents that
+// violate exactly one rule of the validator. The generator uses a seeded
+// random source, so the same seed always creates the same documents.
+type Generator struct {
+	// The nesting depth after which optional fields are omitted and arrays
+	// and maps are created with their minimum size. This limits the size of
+	// documents created for recursive validators.
+	MaxDepth int
+
+	rand      *rand.Rand
+	mutations []mutation
+}
+
+// InvalidDocument is a generated JSON document that violates exactly one rule
+// of a validator.
+type InvalidDocument struct {
+	// The JSON text of the document.
+	Text string
+
+	// The location of the invalid value in the document, such as "staff[0].age".
+	// This is an empty string if the document itself is the invalid value.
+	Path string
+
+	// The tag keyword for the rule that is violated, such as "maxvalue", or
+	// "type" if the value is not the correct type.
+	Rule string
+
+	// The error that the Validate() function is expected to return for the
+	// document.
+	Err error
+}
+
+// A mutation describes a change to a valid document that makes it invalid.
+type mutation struct {
+	path   []any
+	value  any
+	remove bool
+	rule   string
+	err    error
+}
+
+// NewGenerator creates a new document generator using the given seed for
+// the random values in the documents.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{
+		MaxDepth: defaultGeneratorDepth,
+		rand:     rand.New(rand.NewSource(seed)),
+	}
+}
+
+// reset restarts the random source of the generator using the given seed, so
+// it creates the same documents as a new generator with that seed.
+func (g *Generator) reset(seed int64) {
+	g.rand.Seed(seed)
+	g.mutations = nil
+}
+
+// Valid returns a JSON document that is valid for the validator. If the rules
+// of the validator cannot be satisfied, an error is returned.
+func (g *Generator) Valid(i *Item) (string, error) {
+	value, err := g.value(i, nil, 0, false)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(value)
+
+	return string(b), err
+}
+
+// Invalid returns a list of JSON documents that each violate exactly one rule of
+// the validator. Each document is annotated with the rule that is violated and
+// the error expected from the Validate() function. The documents are created by
+// changing a single value in a valid document.
+func (g *Generator) Invalid(i *Item) ([]InvalidDocument, error) {
+	g.mutations = nil
+
+	value, err := g.value(i, []any{}, 0, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]InvalidDocument, 0, len(g.mutations))
+
+	for _, m := range g.mutations {
+		doc := applyMutation(copyValue(value), m.path, m.value, m.remove)
+
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, InvalidDocument{
+			Text: string(b),
+			Path: formatPath(m.path),
+			Rule: m.rule,
+			Err:  m.err,
+		})
+	}
+
+	g.mutations = nil
+
+	return result, nil
+}
+
+// value creates a valid value for the item. The path is the location of the
+// value in the document. If collect is true, the mutations that make this value
+// invalid are recorded in the generator.
+func (g *Generator) value(i *Item, path []any, depth int, collect bool) (any, error) {
+	if i == nil {
+		return nil, ErrNilValidator
+	}
+
+	if depth > maxValidationDepth {
+		return nil, ErrMaxDepthExceeded.Value(depth)
+	}
+
+	// Resolve aliases the same way the validator does.
+	if i.Alias != "" {
+		aliasItem, exists := find(aliasPrefix + i.Alias)
+		if exists && aliasItem.ItemType == TypeStruct {
+			i = aliasItem
+		}
+	}
+
+	// A null value is only valid for a nullable item. A custom type decides
+	// for itself if null is valid.
+	if collect && !i.Nullable && i.ItemType != TypeAny && i.ItemType != TypeCustom {
+		g.mutate(path, nil, "nullable", ErrNullNotAllowed.Context(i.Name))
+	}
+
+	if i.Quoted {
+		return g.quotedValue(i, path, depth, collect)
+	}
+
+	switch i.ItemType {
+	case TypeAny:
+		return g.word(1, 8), nil
+
+	case TypePointer:
+		first := len(g.mutations)
+
+		value, err := g.value(i.BaseType, path, depth+1, collect)
+		if collect {
+			g.pointerMutations(i, path, first)
+		}
+
+		return value, err
+
+	case TypeBool:
+		if collect {
+			g.mutate(path, "maybe", "type", ErrInvalidData.Context(i.Name).Value("maybe"))
+		}
+
+		return g.rand.Intn(2) == 1, nil
+
+	case TypeInt:
+		return g.intValue(i, path, collect)
+
+	case TypeFloat:
+		return g.floatValue(i, path, collect)
+
+	case TypeString:
+		return g.stringValue(i, path, collect)
+
+	case TypeList:
+		return g.listValue(i, path, collect)
+
+	case TypeUUID:
+		if collect {
+			g.mutate(path, "not-a-uuid", "type", ErrInvalidData.Context(i.Name).Value("not-a-uuid"))
+		}
+
+		id, err := uuid.NewRandomFromReader(g.rand)
+
+		return id.String(), err
+
+	case TypeTime:
+		return g.timeValue(i, path, collect)
+
+	case TypeDuration:
+		return g.durationValue(i, path, collect)
+
+	case TypeArray:
+		return g.arrayValue(i, path, depth, collect)
+
+	case TypeTuple:
+		return g.tupleValue(i, path, depth, collect)
+
+	case TypeMap:
+		return g.mapValue(i, path, depth, collect)
+
+	case TypeStruct:
+		return g.structValue(i, path, depth, collect)
+
+	case TypeCustom:
+		return g.customValue(i, path, collect)
+
+	case TypeUnion:
+		return g.unionValue(i, path, depth, collect)
+	}
+
+	return nil, ErrUnimplemented.Context(i.Name).Value(i.ItemType.String())
+}
+
+func (g *Generator) intValue(i *Item, path []any, collect bool) (any, error) {
+	lo, hi := math.MinInt, math.MaxInt
+
+	if i.HasMinValue {
+		lo = intBound(i.MinValue, i.ExclusiveMinValue, true)
+	}
+
+	if i.HasMaxValue {
+		hi = intBound(i.MaxValue, i.ExclusiveMaxValue, false)
+	}
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minvalue greater than maxvalue")
+	}
+
+	// Parse the enumerated values the same way the validator does, and keep
+	// the ones that are valid.
+	enums := []int{}
+	inRange := []int{}
+
+	for _, enum := range i.Enums {
+		n, _ := getIntValue(enum)
+		enums = append(enums, n)
+
+		if i.validateInt(n) == nil {
+			inRange = append(inRange, n)
+		}
+	}
+
+	if collect {
+		g.mutate(path, true, "type", ErrInvalidData.Context(i.Name).Value(true))
+		g.mutate(path, 0.5, "fraction", ErrInvalidData.Context(i.Name).Value(0.5))
+
+		if len(enums) == 0 {
+			if i.HasMinValue && lo > math.MinInt {
+				g.mutate(path, lo-1, "minvalue", i.rangeError(lo-1, true))
+			}
+
+			if i.HasMaxValue && hi < math.MaxInt {
+				g.mutate(path, hi+1, "maxvalue", i.rangeError(hi+1, false))
+			}
+		} else {
+			// Find an in-range value that is not one of the enumerated values.
+			largest := enums[0]
+			for _, n := range enums {
+				largest = max(largest, n)
+			}
+
+			if largest < hi && largest+1 >= lo && (i.MultipleOf == nil || isIntMultiple(largest+1, i.MultipleOf)) {
+				candidate := largest + 1
+				g.mutate(path, candidate, "enum", ErrInvalidEnumeratedValue.Context(i.Name).Value(candidate).Expected(i.Enums))
+			}
+		}
+	}
+
+	if len(i.Enums) > 0 {
+		if len(inRange) == 0 {
+			return nil, ErrCannotGenerate.Context(i.Name).Value("no enumerated value in range")
+		}
+
+		return inRange[g.rand.Intn(len(inRange))], nil
+	}
+
+	// Choose a value near zero, or near the closest limit to zero.
+	center := min(max(0, lo), hi)
+
+	if i.MultipleOf != nil {
+		step, _ := getFloatValue(i.MultipleOf)
+
+		f, ok := g.multipleValue(float64(lo), float64(hi), float64(center), step)
+		if value := int(math.Round(f)); ok && i.validateInt(value) == nil {
+			if collect {
+				check := func(v any) error { return i.validateInt(v.(int)) }
+				g.numericMutation(path, "multipleof", ErrNotMultiple, check, value+1, value-1)
+			}
+
+			return value, nil
+		}
+
+		return nil, ErrCannotGenerate.Context(i.Name).Value("no multiple in range")
+	}
+
+	below := min(uint64(center-lo), generatorSpread)
+	above := min(uint64(hi-center), generatorSpread)
+
+	return center - int(below) + g.rand.Intn(int(below+above)+1), nil
+}
+
+func (g *Generator) floatValue(i *Item, path []any, collect bool) (any, error) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+
+	if i.HasMinValue {
+		lo, _ = getFloatValue(i.MinValue)
+	}
+
+	if i.HasMaxValue {
+		hi, _ = getFloatValue(i.MaxValue)
+	}
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minvalue greater than maxvalue")
+	}
+
+	if collect {
+		g.mutate(path, true, "type", ErrInvalidData.Context(i.Name).Value(true))
+
+		// An exclusive limit is itself out of range. The errors are found by
+		// checking the values, so a decimal value reports its JSON text.
+		if i.HasMinValue && !math.IsInf(lo, 0) {
+			below := lo
+			if !i.ExclusiveMinValue {
+				below = nextFloat(lo, -1)
+			}
+
+			g.mutate(path, below, "minvalue", i.validateFloat(below))
+		}
+
+		if i.HasMaxValue && !math.IsInf(hi, 0) {
+			above := hi
+			if !i.ExclusiveMaxValue {
+				above = nextFloat(hi, 1)
+			}
+
+			g.mutate(path, above, "maxvalue", i.validateFloat(above))
+		}
+	}
+
+	if len(i.Enums) > 0 {
+		return g.floatEnum(i, path, collect)
+	}
+
+	center := math.Min(math.Max(0, lo), hi)
+
+	if i.MultipleOf != nil {
+		step, _ := getFloatValue(i.MultipleOf)
+
+		value, ok := g.multipleValue(lo, hi, center, step)
+		if !ok || i.validateFloat(value) != nil {
+			return nil, ErrCannotGenerate.Context(i.Name).Value("no multiple in range")
+		}
+
+		if collect {
+			check := func(v any) error { return i.validateFloat(v.(float64)) }
+			g.numericMutation(path, "multipleof", ErrNotMultiple, check, value+step/2, value-step/2)
+		}
+
+		return value, nil
+	}
+
+	a := math.Max(lo, center-generatorSpread)
+	b := math.Min(hi, center+generatorSpread)
+
+	// Round to two decimal places when the result is still in range, to
+	// make the documents easier to read.
+	value := a + g.rand.Float64()*(b-a)
+	if rounded := math.Round(value*100) / 100; i.validateFloat(rounded) == nil {
+		value = rounded
+	}
+
+	if i.validateFloat(value) != nil {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minvalue greater than maxvalue")
+	}
+
+	return value, nil
+}
+
+// floatEnum chooses one of the valid enumerated values of a float, and adds a
+// mutation for a value that is not one of them.
+func (g *Generator) floatEnum(i *Item, path []any, collect bool) (any, error) {
+	inRange := []float64{}
+	largest := math.Inf(-1)
+
+	for _, enum := range i.Enums {
+		n, _ := getFloatValue(enum)
+		largest = math.Max(largest, n)
+
+		if i.validateFloat(n) == nil {
+			inRange = append(inRange, n)
+		}
+	}
+
+	if collect {
+		check := func(v any) error { return i.validateFloat(v.(float64)) }
+		g.numericMutation(path, "enum", ErrInvalidEnumeratedValue, check, largest+1, largest+0.5)
+	}
+
+	if len(inRange) == 0 {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("no enumerated value in range")
+	}
+
+	return inRange[g.rand.Intn(len(inRange))], nil
+}
+
+func (g *Generator) stringValue(i *Item, path []any, collect bool) (any, error) {
+	lo, hi := g.lengths(i, 1, 12)
+
+	if collect {
+		g.mutate(path, true, "type", ErrInvalidData.Context(i.Name).Value(true))
+
+		if len(i.Enums) == 0 {
+			if i.HasMinLength && i.MinLength > 0 {
+				short := g.word(i.MinLength-1, i.MinLength-1)
+				g.mutate(path, short, "minlen", ErrValueLengthOutOfRange.Context(i.Name).Value(short))
+			}
+
+			if i.HasMaxLength {
+				long := g.word(i.MaxLength+1, i.MaxLength+1)
+				g.mutate(path, long, "maxlen", ErrValueLengthOutOfRange.Context(i.Name).Value(long))
+			}
+		} else if word, ok := g.nonEnum(i, max(lo, 1), hi); ok && i.validatePattern(word) == nil {
+			g.mutate(path, word, "enum", ErrInvalidEnumeratedValue.Context(i.Name).Value(word).Expected(i.Enums))
+		}
+
+		if i.Pattern != "" {
+			if word, ok := g.nonPattern(i, lo, hi); ok {
+				g.mutate(path, word, "pattern", ErrPatternMismatch.Context(i.Name).Value(word).Expected(i.Pattern))
+			}
+		}
+	}
+
+	if len(i.Enums) > 0 {
+		candidates := []string{}
+
+		for _, enum := range i.Enums {
+			if len(enum) >= lo && len(enum) <= hi && i.validatePattern(enum) == nil {
+				candidates = append(candidates, enum)
+			}
+		}
+
+		if len(candidates) == 0 {
+			return nil, ErrCannotGenerate.Context(i.Name).Value("no enumerated value with valid length")
+		}
+
+		return candidates[g.rand.Intn(len(candidates))], nil
+	}
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minlen greater than maxlen")
+	}
+
+	if i.Pattern != "" {
+		return g.patternValue(i, lo, hi)
+	}
+
+	return g.word(lo, hi), nil
+}
+
+func (g *Generator) listValue(i *Item, path []any, collect bool) (any, error) {
+	// A list always has at least one element, since an empty string is a
+	// list with a single empty element.
+	lo, hi := g.lengths(i, 1, 3)
+	lo = max(lo, 1)
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minlen greater than maxlen")
+	}
+
+	elements := func(count int) []string {
+		list := make([]string, count)
+		for n := range list {
+			if len(i.Enums) > 0 {
+				list[n] = i.Enums[g.rand.Intn(len(i.Enums))]
+			} else {
+				list[n] = g.word(1, 8)
+			}
+		}
+
+		return list
+	}
+
+	if collect {
+		g.mutate(path, true, "type", ErrInvalidData.Context(i.Name).Value(true))
+
+		if i.HasMinLength && i.MinLength > 1 {
+			short := strings.Join(elements(i.MinLength-1), ",")
+			g.mutate(path, short, "minlen", ErrValueLengthOutOfRange.Context(i.Name).Value(short))
+		}
+
+		if i.HasMaxLength {
+			long := strings.Join(elements(i.MaxLength+1), ",")
+			g.mutate(path, long, "maxlen", ErrValueLengthOutOfRange.Context(i.Name).Value(long))
+		}
+
+		if len(i.Enums) > 0 {
+			if word, ok := g.nonEnum(i, 1, 12); ok {
+				list := elements(lo)
+				list[0] = word
+				g.mutate(path, strings.Join(list, ","), "enum", ErrInvalidEnumeratedValue.Context(i.Name).Value(word).Expected(i.Enums))
+			}
+		}
+	}
+
+	return strings.Join(elements(lo+g.rand.Intn(hi-lo+1)), ","), nil
+}
+
+func (g *Generator) timeValue(i *Item, path []any, collect bool) (any, error) {
+	const window = 365 * 24 * time.Hour
+
+	var lo, hi time.Time
+
+	if i.HasMinValue {
+		lo, _ = getTimeValue(i.MinValue)
+	}
+
+	if i.HasMaxValue {
+		hi, _ = getTimeValue(i.MaxValue)
+	}
+
+	switch {
+	case !i.HasMinValue && !i.HasMaxValue:
+		lo, hi = generatorBaseTime.Add(-window), generatorBaseTime.Add(window)
+
+	case !i.HasMinValue:
+		lo = hi.Add(-window)
+
+	case !i.HasMaxValue:
+		hi = lo.Add(window)
+	}
+
+	if lo.After(hi) {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minvalue greater than maxvalue")
+	}
+
+	if collect {
+		g.mutate(path, "yesterday", "type", ErrInvalidData.Context(i.Name).Value("yesterday"))
+
+		if i.HasMinValue {
+			early := lo.Add(-24 * time.Hour).Format(time.RFC3339Nano)
+			g.mutate(path, early, "minvalue", ErrValueOutOfRange.Context(i.Name).Value(early))
+		}
+
+		if i.HasMaxValue {
+			late := hi.Add(24 * time.Hour).Format(time.RFC3339Nano)
+			g.mutate(path, late, "maxvalue", ErrValueOutOfRange.Context(i.Name).Value(late))
+		}
+	}
+
+	seconds := min(int64(hi.Sub(lo)/time.Second), int64(2*window/time.Second))
+	value := lo.Add(time.Duration(g.rand.Int63n(seconds+1)) * time.Second)
+
+	return value.Format(time.RFC3339Nano), nil
+}
+
+func (g *Generator) durationValue(i *Item, path []any, collect bool) (any, error) {
+	// The validator compares durations in milliseconds, so the generated values
+	// are chosen as a whole number of milliseconds.
+	lo, hi := time.Second.Milliseconds(), time.Hour.Milliseconds()
+
+	if i.HasMinValue {
+		d, _ := getDurationValue(i.MinValue)
+		lo = d.Milliseconds()
+	}
+
+	if i.HasMaxValue {
+		d, _ := getDurationValue(i.MaxValue)
+		hi = d.Milliseconds()
+
+		if !i.HasMinValue {
+			lo = min(lo, hi)
+		}
+	} else if i.HasMinValue {
+		hi = lo + time.Hour.Milliseconds()
+	}
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minvalue greater than maxvalue")
+	}
+
+	if collect {
+		g.mutate(path, "forever", "type", ErrInvalidData.Context(i.Name).Value("forever"))
+
+		if i.HasMinValue {
+			short := (time.Duration(lo-1000) * time.Millisecond).String()
+			g.mutate(path, short, "minvalue", ErrValueOutOfRange.Context(i.Name).Value(short))
+		}
+
+		if i.HasMaxValue {
+			long := (time.Duration(hi+1000) * time.Millisecond).String()
+			g.mutate(path, long, "maxvalue", ErrValueOutOfRange.Context(i.Name).Value(long))
+		}
+	}
+
+	// Prefer whole seconds when there is a whole second in the range.
+	ms := lo + g.rand.Int63n(hi-lo+1)
+	if rounded := ms / 1000 * 1000; rounded >= lo {
+		ms = rounded
+	}
+
+	return (time.Duration(ms) * time.Millisecond).String(), nil
+}
+
+func (g *Generator) arrayValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	lo, hi := g.lengths(i, 0, 3)
+	if depth >= g.MaxDepth {
+		hi = lo
+	}
+
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("minlen greater than maxlen")
+	}
+
+	base := i.BaseType
+	first := len(g.mutations)
+
+	var array []any
+
+	// If there are rules for the values as a whole, new arrays are created until
+	// one is valid. The values that match the contains validator are last.
+	for attempt := 0; ; attempt++ {
+		g.mutations = g.mutations[:first]
+
+		count := lo + g.rand.Intn(hi-lo+1)
+		contains := count - g.containsCount(i, count)
+		array = make([]any, count)
+
+		for n := range array {
+			item := base
+			if n >= contains {
+				item = i.Contains
+			}
+
+			element, err := g.value(item, appendPath(path, n), depth+1, collect && n == 0 && item == base)
+			if err != nil {
+				return nil, err
+			}
+
+			array[n] = element
+		}
+
+		if !i.hasElementRules() || i.validArray(array, depth) {
+			break
+		}
+
+		if attempt >= generatorAttempts {
+			return nil, ErrCannotGenerate.Context(i.Name).Value("no valid array values")
+		}
+	}
+
+	count := len(array)
+
+	if collect {
+		g.mutate(path, "text", "type", ErrInvalidData.Context(i.Name).Value("text"))
+
+		if i.HasMinLength && i.MinLength > 0 && i.MinLength <= count {
+			short := copyValue(array[:i.MinLength-1])
+			g.mutate(path, short, "minlen", ErrArrayLengthOutOfRange.Context(i.Name).Value(i.MinLength-1).Expected(i.MinLength))
+		}
+
+		if i.HasMaxLength {
+			long := copyValue(array).([]any)
+
+			for len(long) <= i.MaxLength {
+				element, err := g.value(base, nil, depth+1, false)
+				if err != nil {
+					return nil, err
+				}
+
+				long = append(long, element)
+			}
+
+			g.mutate(path, long, "maxlen", ErrArrayLengthOutOfRange.Context(i.Name).Value(len(long)).Expected(i.MaxLength))
+		}
+
+		if i.hasElementRules() {
+			g.elementMutations(i, path, depth, array)
+			g.checkMutations(i, path, depth, first, array)
+		}
+	}
+
+	return array, nil
+}
+
+func (g *Generator) mapValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	lo, hi := g.lengths(i, 0, 3)
+	if depth >= g.MaxDepth {
+		hi = lo
+	}
+
+	keys, err := g.mapKeys(i, depth, hi)
+	if err != nil {
+		return nil, err
+	}
+
+	hi = min(hi, len(keys))
+	if lo > hi {
+		return nil, ErrCannotGenerate.Context(i.Name).Value("not enough keys")
+	}
+
+	keys = keys[:lo+g.rand.Intn(hi-lo+1)]
+
+	base := i.BaseType
+	result := map[string]any{}
+
+	for n, key := range keys {
+		value, err := g.value(base, appendPath(path, key), depth+1, collect && n == 0)
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	if collect {
+		g.mutate(path, "text", "type", ErrInvalidData.Context(i.Name).Value("text"))
+
+		if err := g.mapMutations(i, path, depth, result); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// mapKeys returns a list of up to count different keys that are valid for the
+// map. The keys are chosen from the enumerated values if there are any, and are
+// otherwise generated from the pattern and key validator of the map.
+func (g *Generator) mapKeys(i *Item, depth int, count int) ([]string, error) {
+	keys := []string{}
+
+	if len(i.Enums) > 0 {
+		for _, n := range g.rand.Perm(len(i.Enums)) {
+			if i.validateKey(i.Enums[n], depth) == nil {
+				keys = append(keys, i.Enums[n])
+			}
+		}
+
+		return keys, nil
+	}
+
+	if i.Pattern == "" && i.KeyType == nil {
+		for n := 1; n <= count; n++ {
+			keys = append(keys, "key"+strconv.Itoa(n))
+		}
+
+		return keys, nil
+	}
+
+	// Generate keys using the key validator, with the pattern of the map if
+	// the key validator does not have its own pattern.
+	key := NewType(TypeString)
+	if i.KeyType != nil {
+		key = i.KeyType.Copy()
+	}
+
+	if key.Pattern == "" {
+		key.Pattern = i.Pattern
+	}
+
+	key.Name = i.Name
+	found := map[string]bool{}
+
+	for attempt := 0; len(keys) < count && attempt < generatorAttempts*count; attempt++ {
+		value, err := g.value(key, nil, depth+1, false)
+		if err != nil {
+			return nil, err
+		}
+
+		text, ok := keyText(value)
+		if ok && !found[text] && i.validateKey(text, depth) == nil {
+			found[text] = true
+			keys = append(keys, text)
+		}
+	}
+
+	return keys, nil
+}
+
+// keyText returns the text of a generated value used as a map key, the same way
+// encoding/json writes the keys of a map.
+func keyText(v any) (string, bool) {
+	switch actual := v.(type) {
+	case string:
+		return actual, true
+
+	case int:
+		return strconv.Itoa(actual), true
+
+	case bool:
+		return strconv.FormatBool(actual), true
+	}
+
+	return "", false
+}
+
+// mapMutations adds the mutations for a map: maps with too few or too many keys,
+// and keys that are not valid for the map. The largest map created has at most
+// generatorSpread keys.
+func (g *Generator) mapMutations(i *Item, path []any, depth int, result map[string]any) error {
+	keys := make([]string, 0, len(result))
+	for key := range result {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	if i.HasMinLength && i.MinLength > 0 {
+		short := map[string]any{}
+		for _, key := range keys[:i.MinLength-1] {
+			short[key] = copyValue(result[key])
+		}
+
+		g.mutate(path, short, "minlen", ErrMapLengthOutOfRange.Context(i.Name).Value(len(short)).Expected(i.MinLength))
+	}
+
+	if i.HasMaxLength && i.MaxLength < generatorSpread {
+		long := copyValue(result).(map[string]any)
+		for n := 1; len(long) <= i.MaxLength; n++ {
+			long["extra"+strconv.Itoa(n)] = nil
+		}
+
+		g.mutate(path, long, "maxlen", ErrMapLengthOutOfRange.Context(i.Name).Value(len(long)).Expected(i.MaxLength))
+	}
+
+	// An invalid key is added to the map, so there must be room for another key.
+	if i.HasMaxLength && len(result) >= i.MaxLength {
+		return nil
+	}
+
+	candidates := map[string]string{}
+
+	if len(i.Enums) > 0 {
+		if word, ok := g.nonEnum(i, 1, 12); ok {
+			candidates[word] = "key"
+		}
+	}
+
+	if i.Pattern != "" {
+		if word, ok := g.nonPattern(i, 1, 12); ok {
+			candidates[word] = "pattern"
+		}
+	}
+
+	// Use the mutations of the key validator that are strings.
+	if i.KeyType != nil {
+		saved := g.mutations
+		g.mutations = nil
+
+		_, err := g.value(i.KeyType, []any{}, depth+1, true)
+		mutations := g.mutations
+		g.mutations = saved
+
+		if err != nil {
+			return err
+		}
+
+		for _, m := range mutations {
+			if word, ok := keyText(m.value); ok && !m.remove {
+				candidates[word] = "key"
+			}
+		}
+
+		// A key that is not text for the key type, such as a word for an
+		// integer key.
+		if i.KeyType.ItemType != TypeString {
+			candidates["text"] = "key"
+		}
+	}
+
+	words := make([]string, 0, len(candidates))
+	for word := range candidates {
+		words = append(words, word)
+	}
+
+	sort.Strings(words)
+
+	for _, word := range words {
+		if _, found := result[word]; found {
+			continue
+		}
+
+		// The expected error is the error for the key, since the other keys and
+		// the value of the new key are valid.
+		err := i.validateKey(word, depth)
+		if err == nil {
+			continue
+		}
+
+		value, verr := g.value(i.BaseType, nil, depth+1, false)
+		if verr != nil {
+			return verr
+		}
+
+		g.mutate(appendPath(path, word), value, candidates[word], err)
+	}
+
+	return nil
+}
+
+// structValue creates a valid value for a structure. Fields are added or removed
+// to satisfy the field group and conditional presence rules, but the fields may not satisfy the
+// rules that compare them with other fields, and a structure type that checks its
+// own values may reject the generated values, so more than one attempt is made to
+// create a value that is accepted.
+func (g *Generator) structValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	first := len(g.mutations)
+	reason := "no values satisfy the field rules"
+
+	for range generatorAttempts {
+		g.mutations = g.mutations[:first]
+
+		result, err := g.structFields(i, path, depth, collect)
+		if err != nil {
+			return nil, err
+		}
+
+		if i.TypeName == "" && !i.hasFieldRules() {
+			return result, nil
+		}
+
+		if err := g.ruleFields(i, path, depth, collect, result); err != nil {
+			return nil, err
+		}
+
+		if err := g.groupedFields(i, path, depth, collect, result); err != nil {
+			return nil, err
+		}
+
+		if err := g.conditionalFields(i, path, depth, collect, result); err != nil {
+			return nil, err
+		}
+
+		g.equalFields(i, result)
+		g.expressionFields(i, result)
+
+		m, err := jsonObject(result)
+		if err != nil {
+			continue
+		}
+
+		keys, _ := i.matchFields(m)
+		if i.failedConditions(m, keys) || i.validateFieldRules(m, keys) != nil || i.validateGroups(m, keys) != nil ||
+			i.validateExpressions(m, keys) != nil {
+			continue
+		}
+
+		if i.TypeName != "" && i.validateSelf(m) != nil {
+			reason = "no values accepted by " + i.TypeName
+
+			continue
+		}
+
+		if collect && i.hasFieldRules() {
+			g.fieldRuleMutations(i, path, depth, result)
+			g.conditionMutations(i, path, depth, result)
+			g.groupMutations(i, path, depth, result)
+			g.checkMutations(i, path, depth, first, result)
+		}
+
+		return result, nil
+	}
+
+	return nil, ErrCannotGenerate.Context(i.Name).Value(reason)
+}
+
+// hasFieldRules reports if the structure has a field group rule or a rule
+// expression, or if any field of the structure has a rule that refers to
+// another field.
+func (i *Item) hasFieldRules() bool {
+	if len(i.Groups) > 0 || len(i.Expressions) > 0 {
+		return true
+	}
+
+	for _, field := range i.Fields {
+		if len(field.FieldRules) > 0 || len(field.Conditions) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// failedConditions reports if any field of the structure does not satisfy its
+// conditional presence rules.
+func (i *Item) failedConditions(m map[string]any, keys map[*Item]string) bool {
+	for _, field := range i.Fields {
+		if _, failed := i.failedCondition(field, m, keys); failed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditionalFields adds the fields that are required by a conditional presence
+// rule, and removes the fields that are excluded by one, along with the mutations
+// of the removed fields. Each change can affect the other rules, so the rules are
+// checked again after each change, up to a limit.
+func (g *Generator) conditionalFields(i *Item, path []any, depth int, collect bool, result map[string]any) error {
+	for range 2 * len(i.Fields) {
+		m, err := jsonObject(result)
+		if err != nil {
+			return err
+		}
+
+		keys, _ := i.matchFields(m)
+		changed := false
+
+		for _, field := range i.Fields {
+			condition, failed := i.failedCondition(field, m, keys)
+			if !failed {
+				continue
+			}
+
+			fieldPath := appendPath(path, field.Name)
+
+			if condition.Op == RuleExcludedIf {
+				delete(result, field.Name)
+				g.dropMutations(fieldPath)
+			} else {
+				value, err := g.value(field, fieldPath, depth+1, collect)
+				if err != nil {
+					return err
+				}
+
+				result[field.Name] = value
+			}
+
+			changed = true
+
+			break
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return nil
+}
+
+// groupedFields adds and removes fields to satisfy the field group rules. A field
+// that is required is not removed, so the rules may still not be satisfied.
+func (g *Generator) groupedFields(i *Item, path []any, depth int, collect bool, result map[string]any) error {
+	for _, group := range i.Groups {
+		present := []*Item{}
+		missing := []*Item{}
+
+		for _, name := range group.Fields {
+			if field := i.exactField(name); field != nil {
+				if _, found := result[name]; found {
+					present = append(present, field)
+				} else {
+					missing = append(missing, field)
+				}
+			}
+		}
+
+		add := []*Item{}
+		remove := []*Item{}
+
+		switch group.Op {
+		case GroupOneOf:
+			if len(present) == 0 && len(missing) > 0 {
+				add = append(add, missing[g.rand.Intn(len(missing))])
+			}
+
+			// Keep a required field if there is one, or else a random one.
+			if len(present) > 1 {
+				keep := g.rand.Intn(len(present))
+				for n, field := range present {
+					if field.Required {
+						keep = n
+
+						break
+					}
+				}
+
+				remove = append(append(remove, present[:keep]...), present[keep+1:]...)
+			}
+
+		case GroupAnyOf:
+			if len(present) == 0 && len(missing) > 0 {
+				add = append(add, missing[g.rand.Intn(len(missing))])
+			}
+
+		case GroupAllOf:
+			add = missing
+
+		case GroupNoneOf:
+			remove = present
+		}
+
+		for _, field := range add {
+			fieldPath := appendPath(path, field.Name)
+
+			value, err := g.value(field, fieldPath, depth+1, collect)
+			if err != nil {
+				return err
+			}
+
+			result[field.Name] = value
+		}
+
+		for _, field := range remove {
+			if !field.Required {
+				delete(result, field.Name)
+				g.dropMutations(appendPath(path, field.Name))
+			}
+		}
+	}
+
+	return nil
+}
+
+// groupMutations adds mutations for the field group rules of the structure. A
+// field of the group is removed or added, so that the group has too few or too
+// many fields present.
+func (g *Generator) groupMutations(i *Item, path []any, depth int, result map[string]any) {
+	for _, group := range i.Groups {
+		for _, name := range group.Fields {
+			field := i.exactField(name)
+			if field == nil {
+				continue
+			}
+
+			if _, found := result[name]; found {
+				if !field.Required && group.Op != GroupNoneOf {
+					g.tryGroupMutation(i, path, depth, result, name, nil, true, group.Op)
+				}
+
+				continue
+			}
+
+			if group.Op == GroupOneOf || group.Op == GroupNoneOf {
+				if value, err := g.value(field, nil, depth+1, false); err == nil {
+					g.tryGroupMutation(i, path, depth, result, name, value, false, group.Op)
+				}
+			}
+		}
+	}
+}
+
+// tryGroupMutation adds a mutation that replaces or removes the value of a field
+// in the structure, if the change causes the structure to be rejected with the
+// error for a field group rule.
+func (g *Generator) tryGroupMutation(i *Item, path []any, depth int, result map[string]any, key string, value any, remove bool, rule string) {
+	doc := copyValue(result).(map[string]any)
+
+	if remove {
+		delete(doc, key)
+	} else {
+		doc[key] = value
+	}
+
+	m, err := jsonObject(doc)
+	if err != nil {
+		return
+	}
+
+	keys, _ := i.matchFields(m)
+
+	if expected := i.validateGroups(m, keys); expected != nil {
+		g.tryMutation(i, path, depth, result, key, value, remove, rule, expected)
+	}
+}
+
+// dropMutations removes the mutations of the value at the path, and of any
+// value inside it.
+func (g *Generator) dropMutations(path []any) {
+	kept := g.mutations[:0]
+
+	for _, m := range g.mutations {
+		if len(m.path) < len(path) || !reflect.DeepEqual(m.path[:len(path)], path) {
+			kept = append(kept, m)
+		}
+	}
+
+	g.mutations = kept
+}
+
+// conditionMutations adds mutations for the conditional presence rules of each
+// field. A field that is required by a rule is removed, and a field that is not
+// required is made required by changing the other field. A field that is not
+// allowed is added, or made not allowed by changing the other field.
+func (g *Generator) conditionMutations(i *Item, path []any, depth int, result map[string]any) {
+	for _, field := range i.Fields {
+		for _, condition := range field.Conditions {
+			_, present := result[field.Name]
+			_, otherPresent := result[condition.Field]
+			expected := conditionError(field, condition)
+
+			switch {
+			case condition.Op == RuleExcludedIf && present,
+				condition.Op == RuleRequiredIf && !present:
+				if !g.tryMutation(i, path, depth, result, condition.Field, condition.Value, false, condition.Op, expected) {
+					if n, err := strconv.ParseFloat(condition.Value, 64); err == nil {
+						g.tryMutation(i, path, depth, result, condition.Field, n, false, condition.Op, expected)
+					}
+				}
+
+			case condition.Op == RuleExcludedIf:
+				if v, err := g.value(field, nil, depth+1, false); err == nil {
+					g.tryMutation(i, path, depth, result, field.Name, v, false, condition.Op, expected)
+				}
+
+			case present:
+				if !field.Required {
+					g.tryMutation(i, path, depth, result, field.Name, nil, true, condition.Op, expected)
+				}
+
+			case condition.Op == RuleRequiredWith && !otherPresent:
+				if other := i.exactField(condition.Field); other != nil {
+					if v, err := g.value(other, nil, depth+1, false); err == nil {
+						g.tryMutation(i, path, depth, result, condition.Field, v, false, condition.Op, expected)
+					}
+				}
+
+			case condition.Op == RuleRequiredWithout && otherPresent:
+				g.tryMutation(i, path, depth, result, condition.Field, nil, true, condition.Op, expected)
+			}
+		}
+	}
+}
+
+// tryMutation adds a mutation that replaces or removes the value of a field in
+// the structure, if the structure with that change is rejected with the expected
+// error. It reports if the mutation was added.
+func (g *Generator) tryMutation(i *Item, path []any, depth int, result map[string]any, key string, value any, remove bool, rule string, expected error) bool {
+	doc := copyValue(result).(map[string]any)
+
+	if remove {
+		delete(doc, key)
+	} else {
+		doc[key] = value
+	}
+
+	m, err := jsonObject(doc)
+	if err != nil {
+		return false
+	}
+
+	err = i.validateValue(m, depth)
+	if err == nil || err.Error() != expected.Error() {
+		return false
+	}
+
+	g.mutations = append(g.mutations, mutation{
+		path:   appendPath(path, key),
+		value:  value,
+		remove: remove,
+		rule:   rule,
+		err:    err,
+	})
+
+	return true
+}
+
+// checkMutations removes the mutations recorded since the first one that do
+// not cause the expected error when they are applied to the structure. A rule
+// that refers to other fields can report a different error when the value of
+// a field is changed or removed.
+func (g *Generator) checkMutations(i *Item, path []any, depth int, first int, result any) {
+	kept := g.mutations[:first]
+
+	for _, mutation := range g.mutations[first:] {
+		doc := applyMutation(copyValue(result), mutation.path[len(path):], mutation.value, mutation.remove)
+
+		var v any
+
+		b, err := json.Marshal(doc)
+		if err == nil {
+			err = decodeJSON(b, &v)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if err := i.validateValue(v, depth); err != nil && err.Error() == mutation.err.Error() {
+			kept = append(kept, mutation)
+		}
+	}
+
+	g.mutations = kept
+}
+
+// equalFields copies the value of a field to each field that must be equal to
+// it, if both are present and the value is valid for the field it is copied
+// to. Values that contain other values are not copied, since the mutations of
+// the original value refer to its contents.
+func (g *Generator) equalFields(i *Item, result map[string]any) {
+	for _, field := range i.Fields {
+		for _, rule := range field.FieldRules {
+			value, present := result[rule.Field]
+			if _, found := result[field.Name]; !found || !present || rule.Op != RuleEqualField {
+				continue
+			}
+
+			switch value.(type) {
+			case map[string]any, []any:
+				continue
+			}
+
+			if field.validateValue(value, 0) == nil {
+				result[field.Name] = value
+			}
+		}
+	}
+}
+
+// ruleFields adds the optional fields that are named by the rule expressions of
+// the structure. Most expressions are false, or cannot be evaluated, when a field
+// they compare is missing, so these fields are always included. The field group
+// and conditional presence rules can still remove them.
+func (g *Generator) ruleFields(i *Item, path []any, depth int, collect bool, result map[string]any) error {
+	list, err := i.expressions()
+	if err != nil || depth >= g.MaxDepth {
+		return nil
+	}
+
+	for _, e := range list {
+		for _, name := range fieldNames(e.root, nil) {
+			field := i.exactField(name)
+			if _, found := result[name]; found || field == nil {
+				continue
+			}
+
+			value, err := g.value(field, appendPath(path, name), depth+1, collect)
+			if err != nil {
+				return err
+			}
+
+			result[name] = value
+		}
+	}
+
+	return nil
+}
+
+// expressionFields changes the values of fields so the comparisons in the rule
+// expressions are true. Each comparison that must be true for an expression to
+// be true, such as "total == sum(.lines[*].amount)" or "high > low", is solved by
+// giving a field on one side a value based on the other side. Other expressions
+// are satisfied by trying more than one set of generated values.
+func (g *Generator) expressionFields(i *Item, result map[string]any) {
+	list, err := i.expressions()
+	if err != nil {
+		return
+	}
+
+	for _, e := range list {
+		for _, comparison := range comparisons(e.root, nil) {
+			g.solveComparison(i, comparison, result)
+		}
+	}
+}
+
+// comparisons returns the comparisons that must all be true for an expression
+// to be true, which are the expression itself or the operands of "&&".
+func comparisons(n node, list []*binaryNode) []*binaryNode {
+	b, ok := n.(*binaryNode)
+	if !ok {
+		return list
+	}
+
+	switch b.op {
+	case "&&":
+		return comparisons(b.right, comparisons(b.left, list))
+
+	case "==", "!=", "<", "<=", ">", ">=":
+		return append(list, b)
+	}
+
+	return list
+}
+
+// The operator that gives the same result when the operands of a comparison
+// are swapped.
+var mirroredOperators = map[string]string{
+	"==": "==",
+	"!=": "!=",
+	"<":  ">",
+	"<=": ">=",
+	">":  "<",
+	">=": "<=",
+}
+
+// solveComparison gives a field a value that makes a comparison true, if it is
+// false. The field on the left side is changed if it can be, and otherwise the
+// field on the right side, using the value of the other side of the comparison.
+// A value is only used if it is valid for the field.
+func (g *Generator) solveComparison(i *Item, c *binaryNode, result map[string]any) {
+	sides := []struct {
+		target, other node
+		op            string
+	}{
+		{c.left, c.right, c.op},
+		{c.right, c.left, mirroredOperators[c.op]},
+	}
+
+	for _, side := range sides {
+		name, ok := side.target.(*fieldNode)
+		if !ok {
+			continue
+		}
+
+		field := i.exactField(name.name)
+		if field == nil || field.Quoted {
+			continue
+		}
+
+		m, err := jsonObject(result)
+		if err != nil {
+			return
+		}
+
+		keys, _ := i.matchFields(m)
+		ctx := &evalContext{object: i.expressionObject(m, keys), now: time.Now()}
+
+		if satisfied, err := c.eval(ctx); err == nil && satisfied == true {
+			return
+		}
+
+		other, err := side.other.eval(ctx)
+		if err != nil {
+			continue
+		}
+
+		if value, ok := comparisonValue(field, side.op, other); ok && field.validateValue(value, 0) == nil {
+			result[field.Name] = value
+
+			return
+		}
+	}
+}
+
+// comparisonValue returns a value for a field that makes a comparison with the
+// given value true, when the field is the left operand. Numbers, times, and
+// durations can be compared with any operator, and other values can only be
+// equal. Numbers are returned as JSON numbers, and integer fields are given
+// whole numbers.
+func comparisonValue(field *Item, op string, v any) (any, bool) {
+	base := field
+	for base.ItemType == TypePointer && base.BaseType != nil {
+		base = base.BaseType
+	}
+
+	// The distance from the other value, for the operators that need a value
+	// above or below it.
+	direction := 0
+
+	switch op {
+	case ">", "!=":
+		direction = 1
+	case "<":
+		direction = -1
+	}
+
+	switch actual := v.(type) {
+	case *big.Rat:
+		if base.ItemType != TypeInt || actual.IsInt() {
+			return decimalNumber(new(big.Rat).Add(actual, big.NewRat(int64(direction), 1)))
+		}
+
+		// An integer field is given the nearest whole number on the side of
+		// the value that satisfies the comparison.
+		floor := new(big.Int).Div(actual.Num(), actual.Denom())
+		ceiling := new(big.Int).Add(floor, big.NewInt(1))
+
+		switch op {
+		case ">", ">=", "!=":
+			return json.Number(ceiling.String()), true
+		case "<", "<=":
+			return json.Number(floor.String()), true
+		}
+
+	case time.Time:
+		return actual.Add(time.Duration(direction) * time.Second).Format(time.RFC3339Nano), true
+
+	case time.Duration:
+		return (actual + time.Duration(direction)*time.Second).String(), true
+
+	case string, bool, nil:
+		if op == "==" {
+			return actual, true
+		}
+	}
+
+	return nil, false
+}
+
+// fieldRuleMutations adds a mutation for each rule that compares a field with
+// another field, when both are present. The field is given a value that is valid
+// for the field, but does not satisfy the rule: the value of the other field,
+// a value just either side of it, or for an equality rule, another value for
+// the field.
+func (g *Generator) fieldRuleMutations(i *Item, path []any, depth int, result map[string]any) {
+	for _, field := range i.Fields {
+		for _, rule := range field.FieldRules {
+			other, present := result[rule.Field]
+			if _, found := result[field.Name]; !found || !present {
+				continue
+			}
+
+			candidates := []any{copyValue(other)}
+
+			for _, direction := range []int{-1, 1} {
+				if value, ok := offsetValue(other, direction); ok {
+					candidates = append(candidates, value)
+				}
+			}
+
+			if rule.Op == RuleEqualField {
+				for range 3 {
+					if value, err := g.value(field, nil, depth+1, false); err == nil {
+						candidates = append(candidates, value)
+					}
+				}
+			}
+
+			for _, candidate := range candidates {
+				doc := copyValue(result).(map[string]any)
+				doc[field.Name] = candidate
+
+				m, err := jsonObject(doc)
+				if err != nil || field.validateValue(m[field.Name], 0) != nil {
+					continue
+				}
+
+				keys, _ := i.matchFields(m)
+
+				err = i.validateFieldRules(m, keys)
+				if err != nil && err.Error() == fieldRuleError(field, rule, m[field.Name]).Error() {
+					g.mutate(appendPath(path, field.Name), candidate, rule.Op, err)
+
+					break
+				}
+			}
+		}
+	}
+}
+
+// offsetValue returns a value just above or below a generated value, in the
+// given direction. Integers and floating point values change by one, and times
+// and durations change by one second. It returns false for any other value.
+func offsetValue(v any, direction int) (any, bool) {
+	switch actual := v.(type) {
+	case int:
+		return actual + direction, true
+
+	case float64:
+		return nextFloat(actual, float64(direction)), true
+
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, actual); err == nil {
+			return t.Add(time.Duration(direction) * time.Second).Format(time.RFC3339Nano), true
+		}
+
+		if d, err := time.ParseDuration(actual); err == nil {
+			return (d + time.Duration(direction)*time.Second).String(), true
+		}
+	}
+
+	return nil, false
+}
+
+// jsonObject converts a generated structure to the JSON object that the
+// validator reads from the text of the document.
+func jsonObject(result map[string]any) (map[string]any, error) {
+	var m map[string]any
+
+	b, err := json.Marshal(result)
+	if err == nil {
+		err = decodeJSON(b, &m)
+	}
+
+	return m, err
+}
+
+func (g *Generator) structFields(i *Item, path []any, depth int, collect bool) (map[string]any, error) {
+	result := map[string]any{}
+
+	if collect {
+		g.mutate(path, "text", "type", ErrInvalidData.Context(i.Name).Value("text"))
+	}
+
+	for _, field := range i.Fields {
+		// Optional fields are included half the time, unless the maximum
+		// depth has been reached.
+		if !field.Required && (depth >= g.MaxDepth || g.rand.Intn(2) == 0) {
+			continue
+		}
+
+		fieldPath := appendPath(path, field.Name)
+
+		value, err := g.value(field, fieldPath, depth+1, collect)
+		if err != nil {
+			return nil, err
+		}
+
+		result[field.Name] = value
+
+		if collect && field.Required {
+			g.mutations = append(g.mutations, mutation{
+				path:   fieldPath,
+				remove: true,
+				rule:   "required",
+				err:    ErrRequired.Value(field.Name),
+			})
+		}
+	}
+
+	if err := g.propertyFields(i, path, depth, collect, result); err != nil {
+		return nil, err
+	}
+
+	// A foreign key must not match a pattern property, and is allowed by the
+	// additional property rule.
+	if collect && !i.AllowForeignKey && i.AdditionalProperties == nil {
+		key := "unexpected"
+		for n := 1; n <= generatorAttempts && (i.hasField(key) || i.patternCount(key) > 0); n++ {
+			key = "unexpected" + strconv.Itoa(n)
+		}
+
+		if !i.hasField(key) && i.patternCount(key) == 0 {
+			g.mutate(appendPath(path, key), true, "foreignkeys", ErrInvalidFieldName.Context(i.Name).Value(key))
+		}
+	}
+
+	// If a field name can be matched by a key with different case, add that
+	// key as a second key for the first field that has one.
+	if collect {
+		for _, field := range i.Fields {
+			value, present := result[field.Name]
+			key := strings.ToUpper(field.Name)
+
+			if present && key != field.Name && i.exactField(key) == nil {
+				if match, _ := i.matchField(key); match == field {
+					g.mutate(appendPath(path, key), copyValue(value), "fieldmatch", ErrFieldNameCollision.Context(i.Name).Value(key).Expected(field.Name))
+
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// customValue creates a value for a custom type. The value is one of the item's
+// enumerated values or limits, or the encoded zero value of the registered type.
+// If none of these are accepted by the type, a value cannot be generated.
+func (g *Generator) customValue(i *Item, path []any, collect bool) (any, error) {
+	custom, found := findCustomType(i.TypeName)
+	if !found {
+		return nil, ErrUnsupportedType.Context(i.Name).Value(i.TypeName)
+	}
+
+	// The candidates are the enumerated values and limits of the item, and
+	// the encoded zero value of the type. The first one that is valid is used.
+	candidates := []any{}
+
+	for _, text := range append(append([]string{}, i.Enums...), limitText(i.HasMinValue, i.MinValue), limitText(i.HasMaxValue, i.MaxValue)) {
+		if text == "" {
+			continue
+		}
+
+		candidates = append(candidates, text)
+
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			candidates = append(candidates, f)
+		}
+	}
+
+	var zero any
+
+	// The zero value is marshaled using a pointer, so marshal methods with a
+	// pointer receiver, such as those of big.Int, are used.
+	if b, err := json.Marshal(reflect.New(custom.goType).Interface()); err == nil && json.Unmarshal(b, &zero) == nil {
+		candidates = append(candidates, zero)
+	}
+
+	var value any
+
+	err := ErrCannotGenerate.Context(i.Name).Value(i.TypeName)
+
+	for _, candidate := range candidates {
+		if i.validateCustom(candidate) == nil {
+			value, err = candidate, nil
+
+			break
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the first of these values that the type does not accept as the
+	// value of the wrong type.
+	if collect {
+		for _, candidate := range []any{true, "?", []any{}} {
+			if err := i.validateCustom(candidate); err != nil {
+				g.mutate(path, candidate, "type", err)
+
+				break
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// limitText returns the text of a minimum or maximum value, or an empty string
+// if the limit is not set.
+func limitText(has bool, limit any) string {
+	if !has {
+		return ""
+	}
+
+	return formatValue(limit)
+}
+
+// quotedValue creates a valid value for an item that is encoded inside a JSON
+// string. The mutations of the value are encoded the same way, and a value that
+// is not encoded is added as an invalid value.
+func (g *Generator) quotedValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	// The null value is not encoded inside a string, so its mutation is only
+	// added for the quoted item.
+	plain := *i
+	plain.Quoted = false
+	plain.Nullable = true
+	first := len(g.mutations)
+
+	value, err := g.value(&plain, path, depth, collect)
+	if err != nil {
+		return nil, err
+	}
+
+	if collect {
+		for n := first; n < len(g.mutations); n++ {
+			if m := &g.mutations[n]; !m.remove && len(m.path) == len(path) {
+				b, _ := json.Marshal(m.value)
+				m.value = string(b)
+			}
+		}
+
+		g.mutate(path, value, "quoted", ErrInvalidData.Context(i.Name).Value(value))
+	}
+
+	b, err := json.Marshal(value)
+
+	return string(b), err
+}
+
+// pointerMutations updates the mutations recorded since the first one for the
+// value of a pointer. The pointer decides if the value can be null, so the null
+// mutation of the value is removed.
+func (g *Generator) pointerMutations(i *Item, path []any, first int) {
+	kept := g.mutations[:first]
+
+	for _, m := range g.mutations[first:] {
+		if m.rule == "nullable" && len(m.path) == len(path) {
+			continue
+		}
+
+		kept = append(kept, m)
+	}
+
+	g.mutations = kept
+}
+
+// mutate records a mutation that replaces the value at the path.
+func (g *Generator) mutate(path []any, value any, rule string, err error) {
+	g.mutations = append(g.mutations, mutation{
+		path:  path,
+		value: value,
+		rule:  rule,
+		err:   err,
+	})
+}
+
+// lengths returns the range of lengths for a generated value, using the
+// default range when the item does not specify a length limit.
+func (g *Generator) lengths(i *Item, lo, hi int) (int, int) {
+	switch {
+	case i.HasMinLength && i.HasMaxLength:
+		return i.MinLength, min(i.MaxLength, i.MinLength+hi)
+
+	case i.HasMinLength:
+		return i.MinLength, i.MinLength + hi
+
+	case i.HasMaxLength:
+		return min(lo, i.MaxLength), min(hi, i.MaxLength)
+	}
+
+	return lo, hi
+}
+
+// word returns a random lower-case word with a length in the given range.
+func (g *Generator) word(lo, hi int) string {
+	length := lo
+	if hi > lo {
+		length += g.rand.Intn(hi - lo + 1)
+	}
+
+	b := make([]byte, length)
+	for n := range b {
+		b[n] = byte('a' + g.rand.Intn(26))
+	}
+
+	return string(b)
+}
+
+// nonEnum returns a word with a length in the given range that does not match
+// any of the enumerated values of the item.
+func (g *Generator) nonEnum(i *Item, lo, hi int) (string, bool) {
+	if lo > hi {
+		return "", false
+	}
+
+	for range 10 {
+		word := g.word(lo, hi)
+		if !i.isEnum(word) {
+			return word, true
+		}
+	}
+
+	return "", false
+}
+
+// isEnum reports if the value matches one of the enumerated values of the
+// item, using the item's case sensitivity.
+func (i *Item) isEnum(value string) bool {
+	for _, enum := range i.Enums {
+		if value == enum || (!i.CaseSensitive && strings.EqualFold(value, enum)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasField reports if the structure item has a field that matches the given
+// name, using the field matching mode of the structure.
+func (i *Item) hasField(name string) bool {
+	if i.exactField(name) != nil {
+		return true
+	}
+
+	field, _ := i.matchField(name)
+
+	return field != nil
+}
+
+// nextFloat returns a value that is clearly outside the limit in the given
+// direction. When adding one does not change the value, the next representable
+// floating point value is used instead.
+func nextFloat(limit float64, direction float64) float64 {
+	if value := limit + direction; value != limit {
+		return value
+	}
+
+	return math.Nextafter(limit, math.Inf(int(direction)))
+}
+
+// appendPath returns a new path with the key or index added to the end.
+func appendPath(path []any, element any) []any {
+	if path == nil {
+		return nil
+	}
+
+	result := make([]any, len(path), len(path)+1)
+	copy(result, path)
+
+	return append(result, element)
+}
+
+// formatPath converts a path to a string like "staff[0].name".
+func formatPath(path []any) string {
+	var b strings.Builder
+
+	for _, element := range path {
+		switch actual := element.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(actual) + "]")
+
+		case string:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+
+			b.WriteString(actual)
+		}
+	}
+
+	return b.String()
+}
+
+// copyValue makes a deep copy of a generated value.
+func copyValue(v any) any {
+	switch actual := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(actual))
+		for key, value := range actual {
+			result[key] = copyValue(value)
+		}
+
+		return result
+
+	case []any:
+		result := make([]any, len(actual))
+		for n, value := range actual {
+			result[n] = copyValue(value)
+		}
+
+		return result
+	}
+
+	return v
+}
+
+// applyMutation replaces or removes the value at the path in the document,
+// and returns the updated document.
+func applyMutation(doc any, path []any, value any, remove bool) any {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch container := doc.(type) {
+	case map[string]any:
+		key, _ := path[0].(string)
+
+		if len(path) == 1 && remove {
+			delete(container, key)
+		} else {
+			container[key] = applyMutation(container[key], path[1:], value, remove)
+		}
+
+	case []any:
+		if n, ok := path[0].(int); ok && n < len(container) {
+			container[n] = applyMutation(container[n], path[1:], value, remove)
+		}
+	}
+
+	return doc
+}
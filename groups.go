@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"strings"
+)
+
+// The field group rules. Each limits which fields of a group of fields in a
+// structure can be present in the JSON object.
+const (
+	// Exactly one of the fields must be present.
+	GroupOneOf = "oneof"
+
+	// At least one of the fields must be present.
+	GroupAnyOf = "anyof"
+
+	// All of the fields must be present.
+	GroupAllOf = "allof"
+
+	// None of the fields can be present.
+	GroupNoneOf = "noneof"
+)
+
+// FieldGroup is a rule for a structure that limits which fields of a group can
+// be present in the JSON object, such as a request that must contain exactly one
+// of "id", "name", or "email". A field is present when its key is in the JSON
+// object and its value is not null.
+type FieldGroup struct {
+	// The rule, such as "oneof" or "anyof".
+	Op string `json:"op"`
+
+	// The names of the fields in the group.
+	Fields []string `json:"fields"`
+}
+
+// isGroup reports if the keyword is one of the field group rules.
+func isGroup(op string) bool {
+	switch op {
+	case GroupOneOf, GroupAnyOf, GroupAllOf, GroupNoneOf:
+		return true
+	}
+
+	return false
+}
+
+// AddGroup adds a rule that limits which of the named fields can be present in
+// the JSON object. The op is one of the field group rules, such as GroupOneOf.
+// For a pointer, array, or map validator, the rule is added to the structure it
+// contains. If the op is not a field group rule, no change is made to the item.
+func (i *Item) AddGroup(op string, fields ...string) *Item {
+	if target := i.matchTarget(); target != nil && isGroup(op) {
+		target.Groups = append(target.Groups, FieldGroup{Op: op, Fields: fields})
+	}
+
+	return i
+}
+
+// verifyGroups reports an error if a field group of the structure is empty or
+// names a field that does not exist in the structure.
+func (i *Item) verifyGroups() error {
+	for _, group := range i.Groups {
+		if !isGroup(group.Op) {
+			return ErrInvalidKeyword.Context(i.Name).Value(group.Op)
+		}
+
+		if len(group.Fields) == 0 {
+			return ErrInvalidGroup.Context(group.Op)
+		}
+
+		for _, name := range group.Fields {
+			if i.exactField(name) == nil {
+				return ErrInvalidFieldName.Context(group.Op).Value(name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateGroups checks the field group rules of the structure, using the key
+// found for each field in the JSON object.
+func (i *Item) validateGroups(m map[string]any, keys map[*Item]string) error {
+	for _, group := range i.Groups {
+		present, missing := i.groupFields(group, m, keys)
+
+		switch group.Op {
+		case GroupOneOf:
+			if len(present) != 1 {
+				return ErrOneOf.Context(i.Name).Value(strings.Join(present, ", ")).Expected(group.Fields)
+			}
+
+		case GroupAnyOf:
+			if len(present) == 0 {
+				return ErrAnyOf.Context(i.Name).Expected(group.Fields)
+			}
+
+		case GroupAllOf:
+			if len(missing) > 0 {
+				return ErrAllOf.Context(i.Name).Value(strings.Join(missing, ", ")).Expected(strings.Join(group.Fields, ", "))
+			}
+
+		case GroupNoneOf:
+			if len(present) > 0 {
+				return ErrNoneOf.Context(i.Name).Value(strings.Join(present, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// groupFields returns the names of the fields of the group that are present
+// in the JSON object, and the names of the fields that are not.
+func (i *Item) groupFields(group FieldGroup, m map[string]any, keys map[*Item]string) (present, missing []string) {
+	for _, name := range group.Fields {
+		key, found := keys[i.exactField(name)]
+		if found && m[key] != nil {
+			present = append(present, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	return present, missing
+}
@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// JSONValidator is implemented by structure types that check their own JSON
+// representation. The validator calls the ValidateJSON() method with the JSON
+// object after all of the rules for the structure's fields are satisfied.
+type JSONValidator interface {
+	ValidateJSON(m map[string]any) error
+}
+
+// SelfValidator is implemented by structure types that check their own values.
+// The validator decodes the JSON object into a new value of the type, and calls
+// the Validate() method after all of the rules for the structure's fields are
+// satisfied.
+type SelfValidator interface {
+	Validate() error
+}
+
+// The interfaces a structure type can implement to check its own values.
+var (
+	jsonValidatorType = reflect.TypeFor[JSONValidator]()
+	selfValidatorType = reflect.TypeFor[SelfValidator]()
+)
+
+// validatorTypes holds the structure types that check their own values, stored
+// by the type name recorded in the validator. Access to the map is serialized
+// by a mutex.
+var validatorTypes = map[string]reflect.Type{}
+
+var validatorTypesLock sync.Mutex
+
+// isSelfValidating reports if the structure type, or a pointer to it, checks
+// its own values.
+func isSelfValidating(t reflect.Type) bool {
+	p := reflect.PointerTo(t)
+
+	return p.Implements(jsonValidatorType) || p.Implements(selfValidatorType)
+}
+
+// storeValidatorType adds a structure type to the registry of types that
+// check their own values.
+func storeValidatorType(name string, t reflect.Type) {
+	validatorTypesLock.Lock()
+	defer validatorTypesLock.Unlock()
+
+	validatorTypes[name] = t
+}
+
+// findValidatorType returns the structure type registered with the given name.
+func findValidatorType(name string) (reflect.Type, bool) {
+	validatorTypesLock.Lock()
+	defer validatorTypesLock.Unlock()
+
+	t, found := validatorTypes[name]
+
+	return t, found
+}
+
+// validateSelf calls the methods of the structure type that check its own
+// values, if the type implements them. The ValidateJSON() method is called
+// first, with the JSON object, where numbers are float64 values as they are
+// for encoding/json. The object is then decoded into a new value of
+// the type, and its Validate() method is called. Numbers are written in their
+// shortest form before they are decoded, so a whole number such as 3.0, which
+// the validator accepts as an integer, can be decoded into an integer field. An error from either method
+// is returned as the cause of an ErrValidationFailed error.
+func (i *Item) validateSelf(m map[string]any) error {
+	t, found := findValidatorType(i.TypeName)
+	if !found {
+		return nil
+	}
+
+	target := reflect.New(t).Interface()
+
+	if v, ok := target.(JSONValidator); ok {
+		object, _ := floatNumbers(m).(map[string]any)
+
+		if err := v.ValidateJSON(object); err != nil {
+			return ErrValidationFailed.Context(i.Name).Cause(err)
+		}
+	}
+
+	if v, ok := target.(SelfValidator); ok {
+		object := normalizeNumbers(m, func(n json.Number) any {
+			return json.Number(numberText(n))
+		})
+
+		b, err := json.Marshal(object)
+		if err == nil {
+			err = json.Unmarshal(b, target)
+		}
+
+		if err != nil {
+			return ErrInvalidData.Context(i.Name).Cause(err)
+		}
+
+		if err := v.Validate(); err != nil {
+			return ErrValidationFailed.Context(i.Name).Cause(err)
+		}
+	}
+
+	return nil
+}
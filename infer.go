@@ -0,0 +1,425 @@
+package validator
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// InferOptions controls how a validator is created from sample JSON documents
+// by the InferWithOptions() function.
+type InferOptions struct {
+	// The largest number of distinct string values that will be proposed as an
+	// enumerated list of values. A string is only treated as an enumeration if
+	// at least one value was seen more than once. Zero disables enumerations.
+	MaxEnumValues int
+
+	// If true, the smallest and largest values and lengths seen in the samples
+	// are set as the minimum and maximum values and lengths in the validator.
+	Bounds bool
+}
+
+// DefaultInferOptions are the options used by the Infer() function.
+var DefaultInferOptions = InferOptions{
+	MaxEnumValues: 5,
+}
+
+// Infer creates a validator from one or more sample JSON documents, using the
+// default inference options. See InferWithOptions() for details.
+func Infer(samples ...string) (*Item, error) {
+	return InferWithOptions(DefaultInferOptions, samples...)
+}
+
+// InferWithOptions creates a validator from one or more sample JSON documents.
+// The validator describes the types seen for each value in the samples. Strings
+// that always contain a UUID, a time, or a duration are given those types. Any
+// object field present in every sample of that object is marked as required.
+// Fields are defined in the order they were first seen in the samples.
+//
+// If a value has different types in different samples, it is given the "any"
+// type. Null values do not contribute to the type of a value.
+func InferWithOptions(options InferOptions, samples ...string) (*Item, error) {
+	if len(samples) == 0 {
+		return nil, ErrInvalidData.Context("infer").Value("no samples")
+	}
+
+	root := &inference{options: &options}
+
+	for _, sample := range samples {
+		decoder := json.NewDecoder(strings.NewReader(sample))
+		decoder.UseNumber()
+
+		if err := root.observe(decoder); err != nil {
+			return nil, err
+		}
+
+		// There must not be anything after the sample value.
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, ErrInvalidData.Context("infer").Value(sample)
+		}
+	}
+
+	return root.item(""), nil
+}
+
+// inference accumulates what has been seen at one location in the sample
+// documents. The location is the root of a document, a field in an object,
+// or the elements of an array.
+type inference struct {
+	options *InferOptions
+
+	// The number of values seen of each kind.
+	nulls   int
+	bools   int
+	numbers int
+	strings int
+	objects int
+	arrays  int
+
+	// For numeric values, the range of values seen and whether they were
+	// all integers. The values are kept exactly, so the bounds of large
+	// integers are not rounded.
+	floats     bool
+	minNumber  json.Number
+	maxNumber  json.Number
+	minNumeric *big.Rat
+	maxNumeric *big.Rat
+
+	// For string values, the distinct values seen (up to the enum limit), the
+	// range of lengths, and the number of strings that had each special format.
+	values       map[string]int
+	minLength    int
+	maxLength    int
+	uuids        int
+	times        int
+	durations    int
+	minTime      time.Time
+	maxTime      time.Time
+	minDuration  time.Duration
+	maxDuration  time.Duration
+	tooManyEnums bool
+
+	// For objects, the fields seen in the order they were first seen, and the
+	// number of objects each field was present in.
+	names   []string
+	fields  map[string]*inference
+	present int
+
+	// For arrays, the accumulated elements and the range of array lengths.
+	elements *inference
+	minItems int
+	maxItems int
+}
+
+// observe reads the next value from the decoder and records it.
+func (n *inference) observe(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch value := token.(type) {
+	case nil:
+		n.nulls++
+
+	case bool:
+		n.bools++
+
+	case json.Number:
+		n.observeNumber(value)
+
+	case string:
+		n.observeString(value)
+
+	case json.Delim:
+		if value == '{' {
+			return n.observeObject(decoder)
+		}
+
+		return n.observeArray(decoder)
+	}
+
+	return nil
+}
+
+func (n *inference) observeNumber(value json.Number) {
+	f, ok := parseNumber(value.String())
+	if !ok {
+		return
+	}
+
+	if strings.ContainsAny(value.String(), ".eE") {
+		n.floats = true
+	}
+
+	if n.numbers == 0 || f.Cmp(n.minNumeric) < 0 {
+		n.minNumeric, n.minNumber = f, value
+	}
+
+	if n.numbers == 0 || f.Cmp(n.maxNumeric) > 0 {
+		n.maxNumeric, n.maxNumber = f, value
+	}
+
+	n.numbers++
+}
+
+func (n *inference) observeString(value string) {
+	length := len(value)
+	if n.strings == 0 || length < n.minLength {
+		n.minLength = length
+	}
+
+	if n.strings == 0 || length > n.maxLength {
+		n.maxLength = length
+	}
+
+	n.strings++
+
+	// Record the distinct values, until there are too many to be an enum.
+	if !n.tooManyEnums {
+		if n.values == nil {
+			n.values = map[string]int{}
+		}
+
+		n.values[value]++
+
+		if len(n.values) > n.options.MaxEnumValues {
+			n.tooManyEnums = true
+			n.values = nil
+		}
+	}
+
+	// Check for the special formats. Empty strings and plain numbers are
+	// never treated as special formats, even though they can be parsed as
+	// a nil UUID or a duration.
+	if value == "" {
+		return
+	}
+
+	if _, err := getUUIDValue(value); err == nil {
+		n.uuids++
+
+		return
+	}
+
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return
+	}
+
+	if d, err := getDurationValue(value); err == nil {
+		if n.durations == 0 || d < n.minDuration {
+			n.minDuration = d
+		}
+
+		if n.durations == 0 || d > n.maxDuration {
+			n.maxDuration = d
+		}
+
+		n.durations++
+
+		return
+	}
+
+	if strings.IndexFunc(value, unicode.IsDigit) < 0 {
+		return
+	}
+
+	if t, err := getTimeValue(value); err == nil {
+		if n.times == 0 || t.Before(n.minTime) {
+			n.minTime = t
+		}
+
+		if n.times == 0 || t.After(n.maxTime) {
+			n.maxTime = t
+		}
+
+		n.times++
+	}
+}
+
+func (n *inference) observeObject(decoder *json.Decoder) error {
+	n.objects++
+
+	if n.fields == nil {
+		n.fields = map[string]*inference{}
+	}
+
+	seen := map[string]bool{}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, _ := token.(string)
+
+		field, found := n.fields[key]
+		if !found {
+			field = &inference{options: n.options}
+			n.fields[key] = field
+			n.names = append(n.names, key)
+		}
+
+		if !seen[key] {
+			field.present++
+			seen[key] = true
+		}
+
+		if err := field.observe(decoder); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing brace.
+	_, err := decoder.Token()
+
+	return err
+}
+
+func (n *inference) observeArray(decoder *json.Decoder) error {
+	count := 0
+
+	if n.elements == nil {
+		n.elements = &inference{options: n.options}
+	}
+
+	for decoder.More() {
+		if err := n.elements.observe(decoder); err != nil {
+			return err
+		}
+
+		count++
+	}
+
+	if n.arrays == 0 || count < n.minItems {
+		n.minItems = count
+	}
+
+	if n.arrays == 0 || count > n.maxItems {
+		n.maxItems = count
+	}
+
+	n.arrays++
+
+	// Consume the closing bracket.
+	_, err := decoder.Token()
+
+	return err
+}
+
+// item creates the validator for everything seen at this location.
+func (n *inference) item(name string) *Item {
+	item := &Item{Name: name, ItemType: TypeAny}
+	bounds := n.options.Bounds
+
+	kinds := 0
+
+	for _, count := range []int{n.bools, n.numbers, n.strings, n.objects, n.arrays} {
+		if count > 0 {
+			kinds++
+		}
+	}
+
+	// If there are no values, or values of different kinds, anything goes.
+	if kinds != 1 {
+		return item
+	}
+
+	// A value that was null in any of the samples can be null.
+	item.Nullable = n.nulls > 0
+
+	switch {
+	case n.bools > 0:
+		item.ItemType = TypeBool
+
+	case n.numbers > 0:
+		item.ItemType = TypeInt
+		if n.floats {
+			item.ItemType = TypeFloat
+		}
+
+		// The bounds are the text of the numbers seen, which is exact for
+		// values of any size.
+		if bounds {
+			item.SetMinValue(numberText(n.minNumber)).SetMaxValue(numberText(n.maxNumber))
+		}
+
+	case n.strings > 0:
+		switch n.strings {
+		case n.uuids:
+			item.ItemType = TypeUUID
+
+		case n.durations:
+			item.ItemType = TypeDuration
+
+			if bounds {
+				item.SetMinValue(n.minDuration.String()).SetMaxValue(n.maxDuration.String())
+			}
+
+		case n.times:
+			item.ItemType = TypeTime
+
+			if bounds {
+				item.SetMinValue(n.minTime.Format(time.RFC3339Nano)).SetMaxValue(n.maxTime.Format(time.RFC3339Nano))
+			}
+
+		default:
+			item.ItemType = TypeString
+
+			if n.isEnum() {
+				item.Enums = n.enums()
+			} else if bounds {
+				item.SetMinLength(n.minLength).SetMaxLength(n.maxLength)
+			}
+		}
+
+	case n.objects > 0:
+		item.ItemType = TypeStruct
+
+		for _, key := range n.names {
+			field := n.fields[key]
+			fieldItem := field.item(key)
+			fieldItem.Required = field.present == n.objects
+
+			item.Fields = append(item.Fields, fieldItem)
+		}
+
+	case n.arrays > 0:
+		item.ItemType = TypeArray
+		item.BaseType = n.elements.item("")
+
+		if bounds {
+			item.SetMinLength(n.minItems).SetMaxLength(n.maxItems)
+		}
+	}
+
+	return item
+}
+
+// isEnum reports if the string values seen are a small closed set. This
+// requires that at least one of the values was seen more than once.
+func (n *inference) isEnum() bool {
+	if n.tooManyEnums || len(n.values) == 0 {
+		return false
+	}
+
+	return n.strings > len(n.values)
+}
+
+// enums returns the distinct string values seen, in sorted order.
+func (n *inference) enums() []string {
+	list := make([]string, 0, len(n.values))
+	for value := range n.values {
+		list = append(list, value)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
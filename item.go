@@ -26,11 +26,23 @@ type Item struct {
 	// are permitted for this item.
 	ItemType Type `json:"type,omitempty"`
 
+	// For a custom type, this is the name of the registered Go type used to
+	// validate the value, such as "net.IP". For a structure created from a Go
+	// type that checks its own values, this is the name of the Go type. For
+	// other types, this is an empty string.
+	TypeName string `json:"type_name,omitempty"`
+
 	// This is a list of the allowed values for this item. This is only
 	// used for string, integer, and the key values for map types. IF there
 	// are no enumerated values (enums), this will be an empty slice.
 	Enums []string `json:"enums,omitempty"`
 
+	// A regular expression that a string value must match. For a map, this is
+	// the pattern for the keys of the map. The expression is not anchored, so
+	// it matches any part of the value unless it starts with "^" and ends with
+	// "$". If there is no pattern, this is an empty string.
+	Pattern string `json:"pattern,omitempty"`
+
 	// This is a list of the fields in the current structure. This is only
 	// used for struct types. This will be an empty slice if there are no fields.
 	Fields []*Item `json:"fields,omitempty"`
@@ -40,6 +52,11 @@ type Item struct {
 	// validator is not a pointer or array, this will be nil.
 	BaseType *Item `json:"base_type,omitempty"`
 
+	// For a map, this is the validator for the keys of the map, if there are
+	// rules for the keys other than the enumerated values and pattern of the
+	// map. For other types, this is nil.
+	KeyType *Item `json:"key_type,omitempty"`
+
 	// If there is a rule specifying a minimum length for a string, array, or
 	// map key set, this will be the minimum length. The HasMinLength boolean
 	// will be true if this value is used.
@@ -64,6 +81,12 @@ type Item struct {
 	// in a json payload, this will be true.
 	Required bool `json:"required,omitempty"`
 
+	// If the value can be a JSON null, this is true. A field that is present
+	// with a null value is not the same as a field that is absent, so a field
+	// can be required and also nullable. Pointers created by New() are always
+	// nullable, as they are for encoding/json.
+	Nullable bool `json:"nullable,omitempty"`
+
 	// If there is a rule specifying that the json can contain field names
 	// not explicitly defined in the validator, this will be true. The default
 	// is false, which means the json cannot contain field names not explicitly
@@ -82,14 +105,113 @@ type Item struct {
 	// If there is a maximum value specified for this item, this is true.
 	HasMaxValue bool `json:"has_max_value,omitempty"`
 
+	// If the minimum value of a number is exclusive, so the value must be
+	// greater than the minimum, this is true.
+	ExclusiveMinValue bool `json:"exclusive_min_value,omitempty"`
+
+	// If the maximum value of a number is exclusive, so the value must be
+	// less than the maximum, this is true.
+	ExclusiveMaxValue bool `json:"exclusive_max_value,omitempty"`
+
+	// If there is a rule specifying a step for a numeric field, the value
+	// must be a multiple of this value, which is greater than zero.
+	MultipleOf any `json:"multiple_of,omitempty"`
+
+	// If a float is checked as an exact decimal number rather than as a
+	// float64, such as for an amount of money, this is true.
+	Decimal bool `json:"decimal,omitempty"`
+
 	// IF there is a rule specifying enumerated values, this is true when
 	// the values are case-sensitive. By default, string values are not
 	// case-sensitive.
 	CaseSensitive bool `json:"case_sensitive,omitempty"`
+
+	// If the value is encoded as a JSON string containing the value, as done
+	// by encoding/json for fields with the ",string" option, this is true. The
+	// rules for this item are applied to the value inside the string.
+	Quoted bool `json:"quoted,omitempty"`
+
+	// How the keys of a JSON object are matched to the names of the fields
+	// of this structure. This is "exact" (the default if empty), "fold" to
+	// ignore case, or the name of a registered normalizer.
+	FieldMatch string `json:"field_match,omitempty"`
+
+	// How a JSON key is matched to the name of this field. If empty, the
+	// field matching mode of the containing structure is used.
+	NameMatch string `json:"name_match,omitempty"`
+
+	// The rules that compare the value of this field with the values of other
+	// fields in the same structure, such as "gtfield=start_date". These are
+	// checked after every field of the structure is valid.
+	FieldRules []FieldRule `json:"field_rules,omitempty"`
+
+	// The rules that decide if this field must be present, or must not be
+	// present, based on the other fields in the same structure, such as
+	// "required_if=payment_type:card".
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// The rules for a structure that limit which fields of a group of fields
+	// can be present, such as "oneof=(id,name,email)".
+	Groups []FieldGroup `json:"groups,omitempty"`
+
+	// The rule expressions for a structure, each of which must be true for
+	// the JSON object, such as "total == sum(.lines[*].amount)".
+	Expressions []string `json:"expressions,omitempty"`
+
+	// The compiled form of the rule expressions, if they have been compiled.
+	compiled []*expression
+
+	// The rules for the values of the keys of a JSON object that are not fields
+	// of the structure, for keys that match a pattern, such as "^x-".
+	PatternProperties []PatternProperty `json:"pattern_properties,omitempty"`
+
+	// The validator for the value of each key of a JSON object that is not a
+	// field of the structure and does not match a pattern property. If this is
+	// set, these keys are allowed even when foreign keys are not.
+	AdditionalProperties *Item `json:"additional_properties,omitempty"`
+
+	// For a union, the validators that a value can match. For other types,
+	// this is an empty slice.
+	Variants []Variant `json:"variants,omitempty"`
+
+	// For a union, the name of the property of a JSON object that selects the
+	// variant used to validate the object. If this is empty, the value is
+	// checked against each of the variants.
+	Discriminator string `json:"discriminator,omitempty"`
+
+	// For a union without a discriminator, how many variants a value must
+	// match: "oneof" (the default if empty) or "anyof".
+	UnionMode string `json:"union_mode,omitempty"`
+
+	// For an array, whether the values must all be different. Objects and
+	// arrays are compared by their contents.
+	Unique bool `json:"unique,omitempty"`
+
+	// For an array of objects, the name of a field that must have a different
+	// value in each object.
+	UniqueBy string `json:"unique_by,omitempty"`
+
+	// For an array, the validator that some of the values must match. The
+	// number of matching values must be at least MinContains, which is one
+	// if it is not set, and at most MaxContains if it is set.
+	Contains       *Item `json:"contains,omitempty"`
+	MinContains    int   `json:"min_contains,omitempty"`
+	HasMinContains bool  `json:"has_min_contains,omitempty"`
+	MaxContains    int   `json:"max_contains,omitempty"`
+	HasMaxContains bool  `json:"has_max_contains,omitempty"`
+
+	// For a tuple, the validators for the values of a JSON array, one for
+	// each position in the array.
+	Items []*Item `json:"items,omitempty"`
+
+	// For a tuple, the validator for the values of the array that follow the
+	// values for each position. If this is nil, no other values are allowed.
+	AdditionalItems *Item `json:"additional_items,omitempty"`
 }
 
 const (
-	typeKeyName = "type"
+	typeKeyName     = "type"
+	typeNameKeyName = "type_name"
 )
 
 // SetRequired sets whether this item is required or not. By default, a json
@@ -114,6 +236,7 @@ func (i *Item) SetMinValue(v any) *Item {
 
 	i.MinValue = v
 	i.HasMinValue = true
+	i.ExclusiveMinValue = false
 
 	return i
 }
@@ -127,6 +250,7 @@ func (i *Item) SetMaxValue(v any) *Item {
 
 	i.MaxValue = v
 	i.HasMaxValue = true
+	i.ExclusiveMaxValue = false
 
 	return i
 }
@@ -218,6 +342,18 @@ func (i *Item) SetMatchCase(b bool) *Item {
 	return i
 }
 
+// SetNullable sets the nullable flag. By default, a JSON null is not a valid
+// value, except for pointers. If the value can be null, set this flag to true.
+func (i *Item) SetNullable(b bool) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.Nullable = b
+
+	return i
+}
+
 // SetForeignKeys sets the allow foreign key flag. By default, foreign keys
 // (field names not defined in the validator) are not allowed. If the
 // validator should instead ignore key values not defined in the validator,
@@ -232,6 +368,19 @@ func (i *Item) SetForeignKeys(b bool) *Item {
 	return i
 }
 
+// SetQuoted sets the quoted flag. When set, the value must be a JSON string
+// that contains the encoded value, such as "42" for an integer. This is how
+// encoding/json writes fields that have the ",string" option in their json tag.
+func (i *Item) SetQuoted(b bool) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.Quoted = b
+
+	return i
+}
+
 // SetField adds a field validator to an existing structure validator. The field
 // validator must have been previously completely defined (you cannot update a
 // field after it is defined in the structure). The index is the zero-based index
@@ -275,25 +424,82 @@ func (i *Item) Copy() *Item {
 	}
 
 	result := &Item{
-		Name:            i.Name,
-		Alias:           i.Alias,
-		ItemType:        i.ItemType,
-		Enums:           append([]string{}, i.Enums...),
-		Fields:          make([]*Item, len(i.Fields)),
-		BaseType:        i.BaseType.Copy(),
-		MinLength:       i.MinLength,
-		MaxLength:       i.MaxLength,
-		MinValue:        i.MinValue,
-		MaxValue:        i.MaxValue,
-		Required:        i.Required,
-		AllowForeignKey: i.AllowForeignKey,
-		HasMinLength:    i.HasMinLength,
-		HasMaxLength:    i.HasMaxLength,
-		HasMinValue:     i.HasMinValue,
-		HasMaxValue:     i.HasMaxValue,
-		CaseSensitive:   i.CaseSensitive,
+		Name:              i.Name,
+		Alias:             i.Alias,
+		ItemType:          i.ItemType,
+		TypeName:          i.TypeName,
+		Enums:             append([]string{}, i.Enums...),
+		Fields:            make([]*Item, len(i.Fields)),
+		BaseType:          i.BaseType.Copy(),
+		KeyType:           i.KeyType.Copy(),
+		Pattern:           i.Pattern,
+		MinLength:         i.MinLength,
+		MaxLength:         i.MaxLength,
+		MinValue:          i.MinValue,
+		MaxValue:          i.MaxValue,
+		Required:          i.Required,
+		Nullable:          i.Nullable,
+		AllowForeignKey:   i.AllowForeignKey,
+		HasMinLength:      i.HasMinLength,
+		HasMaxLength:      i.HasMaxLength,
+		HasMinValue:       i.HasMinValue,
+		HasMaxValue:       i.HasMaxValue,
+		ExclusiveMinValue: i.ExclusiveMinValue,
+		ExclusiveMaxValue: i.ExclusiveMaxValue,
+		MultipleOf:        i.MultipleOf,
+		Decimal:           i.Decimal,
+		CaseSensitive:     i.CaseSensitive,
+		Quoted:            i.Quoted,
+		FieldMatch:        i.FieldMatch,
+		NameMatch:         i.NameMatch,
+	}
+
+	if len(i.FieldRules) > 0 {
+		result.FieldRules = append([]FieldRule{}, i.FieldRules...)
+	}
+
+	if len(i.Conditions) > 0 {
+		result.Conditions = append([]Condition{}, i.Conditions...)
+	}
+
+	for _, group := range i.Groups {
+		result.Groups = append(result.Groups, FieldGroup{Op: group.Op, Fields: append([]string{}, group.Fields...)})
+	}
+
+	// The compiled expressions are not changed once created, so they can be
+	// shared by the copy.
+	if len(i.Expressions) > 0 {
+		result.Expressions = append([]string{}, i.Expressions...)
+		result.compiled = i.compiled
 	}
 
+	for _, property := range i.PatternProperties {
+		result.PatternProperties = append(result.PatternProperties, PatternProperty{Pattern: property.Pattern, Value: property.Value.Copy()})
+	}
+
+	result.AdditionalProperties = i.AdditionalProperties.Copy()
+
+	for _, variant := range i.Variants {
+		result.Variants = append(result.Variants, Variant{Match: variant.Match, Value: variant.Value.Copy()})
+	}
+
+	result.Discriminator = i.Discriminator
+	result.UnionMode = i.UnionMode
+
+	for _, item := range i.Items {
+		result.Items = append(result.Items, item.Copy())
+	}
+
+	result.AdditionalItems = i.AdditionalItems.Copy()
+
+	result.Unique = i.Unique
+	result.UniqueBy = i.UniqueBy
+	result.Contains = i.Contains.Copy()
+	result.MinContains = i.MinContains
+	result.HasMinContains = i.HasMinContains
+	result.MaxContains = i.MaxContains
+	result.HasMaxContains = i.HasMaxContains
+
 	for j, field := range i.Fields {
 		result.Fields[j] = field.Copy()
 	}
@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"bytes"
 	"encoding/json"
+	"slices"
 	"strings"
 )
 
@@ -19,7 +21,7 @@ func (i *Item) String() string {
 
 	var m map[string]any
 
-	err := json.Unmarshal(b, &m)
+	err := decodeJSON(b, &m)
 	if err != nil {
 		return err.Error()
 	}
@@ -56,6 +58,15 @@ func stringify(m map[string]any, toString bool) map[string]any {
 				result[key] = actual
 			}
 
+		case json.Number:
+			if n, err := actual.Int64(); err == nil && key == typeKeyName && toString {
+				typeValue := Type(n)
+
+				result[key] = typeValue.String()
+			} else {
+				result[key] = actual
+			}
+
 		case int:
 			if key == typeKeyName && toString {
 				t := Type(actual)
@@ -69,6 +80,9 @@ func stringify(m map[string]any, toString bool) map[string]any {
 			if key == typeKeyName && !toString {
 				if t, err := TypeFromString(actual); err == nil {
 					result[key] = t
+				} else if _, found := findCustomType(actual); found {
+					result[key] = TypeCustom
+					result[typeNameKeyName] = actual
 				}
 			} else {
 				result[key] = actual
@@ -92,9 +106,32 @@ func stringify(m map[string]any, toString bool) map[string]any {
 		}
 	}
 
+	// A registered custom type is written using the name of the type.
+	if name, ok := result[typeNameKeyName].(string); ok && toString && result[typeKeyName] == TypeNames[TypeCustom] {
+		if _, found := findCustomType(name); found {
+			result[typeKeyName] = name
+			delete(result, typeNameKeyName)
+		}
+	}
+
 	return result
 }
 
+// decodeJSON reads a JSON value, keeping each number as a json.Number so its
+// exact text is available. The text is checked first, so a badly formed value
+// reports the same error as json.Unmarshal, including any text that follows
+// the value.
+func decodeJSON(data []byte, v any) error {
+	if !json.Valid(data) {
+		return json.Unmarshal(data, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(v)
+}
+
 // NewJSON converts a JSON byte array into a validator item. IF the JSON did
 // not contain a valid validator item, it will return an error.
 func NewJSON(data []byte) (*Item, error) {
@@ -104,8 +141,9 @@ func NewJSON(data []byte) (*Item, error) {
 		m    map[string]any
 	)
 
-	// First, unmarshal the JSON into a map.
-	err = json.Unmarshal(data, &m)
+	// First, unmarshal the JSON into a map. Numbers are kept as their text,
+	// so large integer limits are not rounded.
+	err = decodeJSON(data, &m)
 	if err != nil {
 		return nil, err
 	}
@@ -126,7 +164,7 @@ func NewJSON(data []byte) (*Item, error) {
 	}
 
 	// Finally, unmarshal the JSON back into the validator item.
-	err = json.Unmarshal(data, &item)
+	err = decodeJSON(data, &item)
 	if err != nil {
 		return nil, err
 	}
@@ -161,23 +199,63 @@ func checkFields(m map[string]any) error {
 	// the json tag for the Item type is modified, it should be updated
 	// in this list as well.
 	fieldNames := map[string]bool{
-		"name":              true,
-		"alias":             true,
-		"type":              true,
-		"fields":            true,
-		"min_value":         true,
-		"has_min_value":     true,
-		"max_value":         true,
-		"has_max_value":     true,
-		"base_type":         true,
-		"min_length":        true,
-		"has_min_length":    true,
-		"max_length":        true,
-		"has_max_length":    true,
-		"enums":             true,
-		"required":          true,
-		"allow_foreign_key": true,
-		"case_sensitive":    true,
+		"name":                  true,
+		"alias":                 true,
+		"type":                  true,
+		"type_name":             true,
+		"fields":                true,
+		"min_value":             true,
+		"has_min_value":         true,
+		"max_value":             true,
+		"has_max_value":         true,
+		"exclusive_min_value":   true,
+		"exclusive_max_value":   true,
+		"multiple_of":           true,
+		"decimal":               true,
+		"base_type":             true,
+		"min_length":            true,
+		"has_min_length":        true,
+		"max_length":            true,
+		"has_max_length":        true,
+		"enums":                 true,
+		"pattern":               true,
+		"key_type":              true,
+		"required":              true,
+		"nullable":              true,
+		"allow_foreign_key":     true,
+		"case_sensitive":        true,
+		"quoted":                true,
+		"field_match":           true,
+		"name_match":            true,
+		"field_rules":           true,
+		"conditions":            true,
+		"groups":                true,
+		"expressions":           true,
+		"pattern_properties":    true,
+		"additional_properties": true,
+		"variants":              true,
+		"discriminator":         true,
+		"union_mode":            true,
+		"items":                 true,
+		"additional_items":      true,
+		"unique":                true,
+		"unique_by":             true,
+		"contains":              true,
+		"min_contains":          true,
+		"has_min_contains":      true,
+		"max_contains":          true,
+		"has_max_contains":      true,
+	}
+
+	// The keys whose values are validator items, which are checked the same way.
+	itemNames := map[string]bool{
+		"fields":                true,
+		"base_type":             true,
+		"key_type":              true,
+		"additional_properties": true,
+		"items":                 true,
+		"additional_items":      true,
+		"contains":              true,
 	}
 
 	// Verify all field names are valid
@@ -186,6 +264,20 @@ func checkFields(m map[string]any) error {
 			return ErrInvalidValidator.Context(name).Value("invalid field name")
 		}
 
+		// Each pattern property has a pattern and a validator for the values,
+		// and each variant has a match value and a validator.
+		if name == "pattern_properties" || name == "variants" {
+			if err := checkEntries(name, value, "pattern", "match"); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if !itemNames[name] {
+			continue
+		}
+
 		if subMap, ok := value.(map[string]any); ok {
 			if err := checkFields(subMap); err != nil {
 				return err
@@ -206,6 +298,34 @@ func checkFields(m map[string]any) error {
 	return nil
 }
 
+// checkEntries verifies the field names of a list of entries in a validator,
+// such as the pattern properties, and of the validator for the value of each
+// one. Each entry has a "value" validator and can have the other names given.
+func checkEntries(context string, value any, names ...string) error {
+	list, _ := value.([]any)
+
+	for _, element := range list {
+		m, ok := element.(map[string]any)
+		if !ok {
+			return ErrInvalidValidator.Context(context).Value("invalid entry")
+		}
+
+		for name, v := range m {
+			if name != "value" && !slices.Contains(names, name) {
+				return ErrInvalidValidator.Context(name).Value("invalid field name")
+			}
+
+			if subMap, ok := v.(map[string]any); ok && name == "value" {
+				if err := checkFields(subMap); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // check is an internal validator for validator structures.
 func check(i *Item) error {
 	if i == nil {
@@ -217,6 +337,10 @@ func check(i *Item) error {
 		return ErrInvalidValidator.Context("type").Value("missing or invalid type")
 	}
 
+	if i.ItemType == TypeCustom && i.TypeName == "" {
+		return ErrInvalidValidator.Context("type_name").Value("missing custom type name")
+	}
+
 	// Check subordinate items.
 	if err := check(i.BaseType); err != nil {
 		return err
@@ -228,6 +352,108 @@ func check(i *Item) error {
 		}
 	}
 
+	// Map keys are strings, so the key validator must be for a type that can
+	// be written as text.
+	if i.KeyType != nil {
+		if err := check(i.KeyType); err != nil {
+			return err
+		}
+
+		if i.ItemType != TypeMap || !isKeyType(i.KeyType.ItemType) {
+			return ErrInvalidValidator.Context("key_type").Value(i.KeyType.ItemType.String())
+		}
+	}
+
+	if i.Pattern != "" {
+		if _, err := compilePattern(i.Pattern); err != nil {
+			return ErrInvalidValidator.Context("pattern").Value(i.Pattern)
+		}
+	}
+
+	// The rules for the keys that are not fields only apply to structures.
+	if len(i.PatternProperties) > 0 || i.AdditionalProperties != nil {
+		if i.ItemType != TypeStruct {
+			return ErrInvalidValidator.Context("additional_properties").Value(i.ItemType.String())
+		}
+
+		if err := check(i.AdditionalProperties); err != nil {
+			return err
+		}
+
+		for _, property := range i.PatternProperties {
+			if _, err := compilePattern(property.Pattern); err != nil {
+				return ErrInvalidValidator.Context("pattern_properties").Value(property.Pattern)
+			}
+
+			if err := check(property.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A union must have variants, each of which is a valid validator.
+	if err := i.verifyUnion(); err != nil {
+		return err
+	}
+
+	for _, variant := range i.Variants {
+		if err := check(variant.Value); err != nil {
+			return err
+		}
+	}
+
+	// A tuple must have positions, each of which is a valid validator.
+	if err := i.verifyTuple(); err != nil {
+		return err
+	}
+
+	for _, item := range i.Items {
+		if err := check(item); err != nil {
+			return err
+		}
+	}
+
+	if err := check(i.AdditionalItems); err != nil {
+		return err
+	}
+
+	// The exclusive limits and the step only apply to numbers.
+	if err := i.verifyNumeric(); err != nil {
+		return err
+	}
+
+	// The rules for the values of an array as a whole only apply to arrays.
+	if err := i.verifyElements(); err != nil {
+		return err
+	}
+
+	if err := check(i.Contains); err != nil {
+		return err
+	}
+
+	// The cross-field comparison rules must refer to fields of the structure.
+	if err := i.verifyFieldRules(); err != nil {
+		return err
+	}
+
+	if err := i.verifyGroups(); err != nil {
+		return err
+	}
+
+	// The rule expressions are compiled once, when the validator is loaded.
+	if err := i.compileRules(); err != nil {
+		return err
+	}
+
+	// The field matching modes must be known.
+	if _, found := findNormalizer(i.FieldMatch); !found {
+		return ErrInvalidValidator.Context("field_match").Value(i.FieldMatch)
+	}
+
+	if _, found := findNormalizer(i.NameMatch); !found {
+		return ErrInvalidValidator.Context("name_match").Value(i.NameMatch)
+	}
+
 	// If the min or max lengths are non-zero, verify that the flag is
 	// set indicating they exit.
 	if i.MinLength > 0 && !i.HasMinLength {
@@ -0,0 +1,228 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Field matching modes. These control how the keys in a JSON object are
+// matched to the field names of a structure validator. The name of any
+// normalizer registered with RegisterNormalizer() can also be used.
+const (
+	// Keys must match the field name exactly. This is the default.
+	MatchExact = "exact"
+
+	// Keys match the field name ignoring case, the same way encoding/json
+	// matches keys to structure fields.
+	MatchFold = "fold"
+)
+
+// Normalizers are functions that convert a key or field name to the form
+// used to compare them. They are stored by name, and access to the map is
+// serialized by a mutex.
+var normalizers = map[string]func(string) string{
+	MatchExact: func(name string) string { return name },
+	MatchFold:  foldName,
+}
+
+var normalizerLock sync.Mutex
+
+// RegisterNormalizer adds a named field matching mode. A key in a JSON object
+// matches a field when the normalizer returns the same value for the key and
+// the field name. For example, a normalizer that removes "_" and "-" characters
+// and converts the name to lower case allows "first_name" to match a field
+// named "firstName". The built-in modes "exact" and "fold" cannot be replaced.
+func RegisterNormalizer(name string, normalizer func(string) string) error {
+	if name == "" || name == MatchExact || name == MatchFold || normalizer == nil {
+		return ErrInvalidName.Value(name)
+	}
+
+	normalizerLock.Lock()
+	defer normalizerLock.Unlock()
+
+	normalizers[name] = normalizer
+
+	return nil
+}
+
+// findNormalizer returns the normalizer for a field matching mode. An empty
+// mode is the same as exact matching.
+func findNormalizer(mode string) (func(string) string, bool) {
+	if mode == "" {
+		mode = MatchExact
+	}
+
+	normalizerLock.Lock()
+	defer normalizerLock.Unlock()
+
+	normalizer, found := normalizers[mode]
+
+	return normalizer, found
+}
+
+// foldName converts each character of the name to the smallest character that
+// is equal to it under Unicode case folding. Two names that are equal ignoring
+// case, as reported by strings.EqualFold(), have the same folded name.
+func foldName(name string) string {
+	return strings.Map(func(r rune) rune {
+		smallest := r
+
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			smallest = min(smallest, f)
+		}
+
+		return smallest
+	}, name)
+}
+
+// SetFieldMatch sets how the keys of a JSON object are matched to the fields
+// of this structure validator. The mode is MatchExact, MatchFold, or the name
+// of a normalizer registered with RegisterNormalizer(). Fields that have their
+// own mode set with SetNameMatch() use that mode instead. For a pointer, array,
+// or map validator, the mode is set for the structure it contains.
+func (i *Item) SetFieldMatch(mode string) *Item {
+	if target := i.matchTarget(); target != nil {
+		target.FieldMatch = mode
+	}
+
+	return i
+}
+
+// matchTarget returns the structure that a field matching mode applies to,
+// found by following pointers, array elements, and map values. If there is
+// no structure, it returns nil.
+func (i *Item) matchTarget() *Item {
+	for i != nil && i.ItemType != TypeStruct && i.BaseType != nil {
+		i = i.BaseType
+	}
+
+	if i == nil || i.ItemType != TypeStruct {
+		return nil
+	}
+
+	return i
+}
+
+// SetNameMatch sets how a JSON key is matched to the name of this field,
+// overriding the mode set for the structure that contains it.
+func (i *Item) SetNameMatch(mode string) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.NameMatch = mode
+
+	return i
+}
+
+// SetFieldMatchAll sets the field matching mode for this structure validator
+// and every structure nested within it, including the structures in arrays,
+// maps, and pointers. References to other validators by alias are not changed.
+func (i *Item) SetFieldMatchAll(mode string) *Item {
+	if i == nil || i.Alias != "" {
+		return i
+	}
+
+	if i.ItemType == TypeStruct {
+		i.FieldMatch = mode
+	}
+
+	i.BaseType.SetFieldMatchAll(mode)
+
+	for _, field := range i.Fields {
+		field.SetFieldMatchAll(mode)
+	}
+
+	return i
+}
+
+// matchFields returns the key in the JSON object that is used for each field
+// of the structure validator. A key that exactly matches a field name is used
+// first, and then the keys are matched using the field matching mode of each
+// field, in sorted order. If a key does not match any field, or any pattern
+// property or additional property rule, and foreign keys are not allowed, an
+// error is returned. If two keys match the same field, an
+// error is returned for the second key.
+func (i *Item) matchFields(m map[string]any) (map[*Item]string, error) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make(map[*Item]string, len(i.Fields))
+	unmatched := []string{}
+
+	for _, key := range keys {
+		if field := i.exactField(key); field != nil {
+			result[field] = key
+		} else {
+			unmatched = append(unmatched, key)
+		}
+	}
+
+	for _, key := range unmatched {
+		field, err := i.matchField(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if field == nil {
+			properties, err := i.properties(key)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(properties) == 0 && !i.AllowForeignKey {
+				return nil, ErrInvalidFieldName.Context(i.Name).Value(key)
+			}
+
+			continue
+		}
+
+		if _, found := result[field]; found {
+			return nil, ErrFieldNameCollision.Context(i.Name).Value(key).Expected(field.Name)
+		}
+
+		result[field] = key
+	}
+
+	return result, nil
+}
+
+// exactField returns the field with exactly the given name, or nil if there
+// is no such field.
+func (i *Item) exactField(key string) *Item {
+	for _, field := range i.Fields {
+		if field.Name == key {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// matchField returns the first field that matches the key using the field
+// matching mode for the field, or nil if there is no matching field.
+func (i *Item) matchField(key string) (*Item, error) {
+	for _, field := range i.Fields {
+		mode := field.NameMatch
+		if mode == "" {
+			mode = i.FieldMatch
+		}
+
+		normalize, found := findNormalizer(mode)
+		if !found {
+			return nil, ErrInvalidFieldMatch.Context(field.Name).Value(mode)
+		}
+
+		if normalize(key) == normalize(field.Name) {
+			return field, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,421 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	// The relative tolerance used to decide if a floating point value is a multiple
+	// of the step, so values like 0.3 are multiples of 0.1 even though neither can
+	// be represented exactly.
+	multipleTolerance = 1e-9
+
+	// The largest exponent allowed in a number that is converted to an exact value.
+	// This is far larger than a float64 can hold, but keeps a number such as 1e9999999
+	// from using a large amount of memory when it is expanded.
+	maxExponent = 1000
+)
+
+// SetGreaterThan sets the exclusive minimum value for this item, so a value
+// must be greater than v. This replaces any minimum value.
+func (i *Item) SetGreaterThan(v any) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.SetMinValue(v)
+	i.ExclusiveMinValue = true
+
+	return i
+}
+
+// SetLessThan sets the exclusive maximum value for this item, so a value must
+// be less than v. This replaces any maximum value.
+func (i *Item) SetLessThan(v any) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.SetMaxValue(v)
+	i.ExclusiveMaxValue = true
+
+	return i
+}
+
+// SetMultipleOf sets the step that a numeric value must be a multiple of. The
+// step must be greater than zero.
+func (i *Item) SetMultipleOf(v any) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.MultipleOf = v
+
+	return i
+}
+
+// SetDecimal sets whether the value of a float item is checked as an exact
+// decimal number, rather than as a float64. This is used for values such as
+// amounts of money, where the minimum and maximum values, the step, and the
+// enumerated values must be compared without rounding.
+func (i *Item) SetDecimal(flag bool) *Item {
+	if i == nil {
+		return nil
+	}
+
+	if i.ItemType == TypeFloat {
+		i.Decimal = flag
+	}
+
+	return i
+}
+
+// validateInt checks an integer value against the rules of the item. This is
+// used for the values created by the generator.
+func (i *Item) validateInt(value int) error {
+	return i.validateInteger(big.NewInt(int64(value)))
+}
+
+// validateInteger checks an integer value of any size against the minimum and
+// maximum values, the step, and the enumerated values of the item. The value is
+// compared exactly, so limits such as the largest int64 are enforced exactly.
+func (i *Item) validateInteger(value *big.Int) error {
+	exact := new(big.Rat).SetInt(value)
+
+	if err := i.validateDecimalBounds(exact, value); err != nil {
+		return err
+	}
+
+	if len(i.Enums) == 0 {
+		return nil
+	}
+
+	for _, enum := range i.Enums {
+		if enumValue, err := getIntegerValue(enum); err == nil && value.Cmp(enumValue) == 0 {
+			return nil
+		}
+	}
+
+	return ErrInvalidEnumeratedValue.Context(i.Name).Value(value).Expected(i.Enums)
+}
+
+// validateFloat checks a floating point value against the minimum and maximum
+// values, the step, and the enumerated values of the item. A decimal item checks
+// the exact decimal text of the value as it is written in JSON instead.
+func (i *Item) validateFloat(value float64) error {
+	if i.Decimal {
+		// Check the value as it is written in JSON, so errors report the same
+		// text as when the JSON is validated.
+		b, _ := json.Marshal(value)
+
+		exact, err := getDecimalValue(json.Number(b))
+		if err != nil {
+			return ErrInvalidData.Context(i.Name).Value(value)
+		}
+
+		return i.validateDecimal(exact, json.Number(b))
+	}
+
+	if i.HasMinValue {
+		t, _ := getFloatValue(i.MinValue)
+		if value < t || value == t && i.ExclusiveMinValue {
+			return i.rangeError(value, true)
+		}
+	}
+
+	if i.HasMaxValue {
+		t, _ := getFloatValue(i.MaxValue)
+		if value > t || value == t && i.ExclusiveMaxValue {
+			return i.rangeError(value, false)
+		}
+	}
+
+	if i.MultipleOf != nil {
+		if step, _ := getFloatValue(i.MultipleOf); !isMultiple(value, step) {
+			return ErrNotMultiple.Context(i.Name).Value(value).Expected("multiple of " + formatValue(i.MultipleOf))
+		}
+	}
+
+	if len(i.Enums) == 0 {
+		return nil
+	}
+
+	for _, enum := range i.Enums {
+		if enumValue, err := getFloatValue(enum); err == nil && value == enumValue {
+			return nil
+		}
+	}
+
+	return ErrInvalidEnumeratedValue.Context(i.Name).Value(value).Expected(i.Enums)
+}
+
+// validateDecimal checks an exact decimal value against the rules of a decimal
+// item. The original value is used in any error, so it is reported as written.
+func (i *Item) validateDecimal(value *big.Rat, original any) error {
+	if err := i.validateDecimalBounds(value, original); err != nil {
+		return err
+	}
+
+	if len(i.Enums) == 0 {
+		return nil
+	}
+
+	for _, enum := range i.Enums {
+		if enumValue, err := getDecimalValue(enum); err == nil && value.Cmp(enumValue) == 0 {
+			return nil
+		}
+	}
+
+	return ErrInvalidEnumeratedValue.Context(i.Name).Value(original).Expected(i.Enums)
+}
+
+// validateDecimalBounds checks an exact value against the minimum and maximum
+// values and the step of the item. The step is checked exactly, without the
+// tolerance used for a float64.
+func (i *Item) validateDecimalBounds(value *big.Rat, original any) error {
+	if i.HasMinValue {
+		if t, err := getDecimalValue(i.MinValue); err == nil {
+			if c := value.Cmp(t); c < 0 || c == 0 && i.ExclusiveMinValue {
+				return i.rangeError(original, true)
+			}
+		}
+	}
+
+	if i.HasMaxValue {
+		if t, err := getDecimalValue(i.MaxValue); err == nil {
+			if c := value.Cmp(t); c > 0 || c == 0 && i.ExclusiveMaxValue {
+				return i.rangeError(original, false)
+			}
+		}
+	}
+
+	if i.MultipleOf != nil {
+		if step, err := getDecimalValue(i.MultipleOf); err == nil && !isExactMultiple(value, step) {
+			return ErrNotMultiple.Context(i.Name).Value(original).Expected("multiple of " + formatValue(i.MultipleOf))
+		}
+	}
+
+	return nil
+}
+
+// rangeError returns the error for a value that is outside the minimum or the
+// maximum value. An exclusive bound is included in the error, so the error says
+// whether the bound itself is allowed.
+func (i *Item) rangeError(value any, lower bool) error {
+	err := ErrValueOutOfRange.Context(i.Name).Value(value)
+
+	switch {
+	case lower && i.ExclusiveMinValue:
+		return err.Expected("greater than " + formatValue(i.MinValue))
+
+	case !lower && i.ExclusiveMaxValue:
+		return err.Expected("less than " + formatValue(i.MaxValue))
+	}
+
+	return err
+}
+
+// isMultiple reports if a value is a multiple of the step, within a tolerance
+// relative to the size of the quotient.
+func isMultiple(value, step float64) bool {
+	if step <= 0 {
+		return false
+	}
+
+	q := value / step
+
+	return math.Abs(q-math.Round(q)) <= multipleTolerance*math.Max(1, math.Abs(q))
+}
+
+// isIntMultiple reports if an integer is a multiple of the step. The integer
+// and the step are compared exactly, so large integers are not rounded.
+func isIntMultiple(value int, step any) bool {
+	exact, err := getDecimalValue(step)
+
+	return err == nil && isExactMultiple(new(big.Rat).SetInt64(int64(value)), exact)
+}
+
+// isExactMultiple reports if a value is an exact multiple of the step.
+func isExactMultiple(value, step *big.Rat) bool {
+	if step.Sign() <= 0 {
+		return false
+	}
+
+	return new(big.Rat).Quo(value, step).IsInt()
+}
+
+// numberText returns the text of a JSON number in a single form, so the same
+// value has the same text however it is written. For example, 1, 1.0, and 1e0
+// are all "1". A value that is not a valid number is returned as it is.
+func numberText(n json.Number) string {
+	r, ok := parseNumber(string(n))
+	if !ok {
+		return string(n)
+	}
+
+	if r.IsInt() {
+		return r.Num().String()
+	}
+
+	// The denominator of a decimal number is a product of twos and fives, so
+	// there are no more fractional digits than the bits in the denominator.
+	text := r.FloatString(r.Denom().BitLen())
+
+	return strings.TrimRight(text, "0")
+}
+
+// floatNumbers returns a copy of a JSON value with each JSON number in it
+// converted to a float64, as encoding/json decodes a number into an any value.
+func floatNumbers(v any) any {
+	return normalizeNumbers(v, func(n json.Number) any {
+		f, _ := n.Float64()
+
+		return f
+	})
+}
+
+// normalizeNumbers returns a copy of a JSON value with each JSON number in it,
+// including those in arrays and objects, replaced by the result of convert.
+func normalizeNumbers(v any, convert func(json.Number) any) any {
+	switch actual := v.(type) {
+	case json.Number:
+		return convert(actual)
+
+	case map[string]any:
+		result := make(map[string]any, len(actual))
+		for key, value := range actual {
+			result[key] = normalizeNumbers(value, convert)
+		}
+
+		return result
+
+	case []any:
+		result := make([]any, len(actual))
+		for n, value := range actual {
+			result[n] = normalizeNumbers(value, convert)
+		}
+
+		return result
+	}
+
+	return v
+}
+
+// verifyNumeric reports an error if the step is not a number greater than zero,
+// or if the numeric rules are used with a type that is not a number.
+func (i *Item) verifyNumeric() error {
+	numeric := i.ItemType == TypeInt || i.ItemType == TypeFloat
+
+	if (i.ExclusiveMinValue || i.ExclusiveMaxValue) && !numeric {
+		return ErrInvalidValidator.Context("exclusive_min_value").Value(i.ItemType.String())
+	}
+
+	if i.Decimal && i.ItemType != TypeFloat {
+		return ErrInvalidValidator.Context("decimal").Value(i.ItemType.String())
+	}
+
+	if i.MultipleOf == nil {
+		return nil
+	}
+
+	if step, err := getDecimalValue(i.MultipleOf); err != nil || step.Sign() <= 0 || !numeric {
+		return ErrInvalidValidator.Context("multiple_of").Value(i.MultipleOf)
+	}
+
+	return nil
+}
+
+// describeBounds describes the minimum and maximum values of a number, such as
+// "greater than 0 and at most 10".
+func describeBounds(i *Item) string {
+	if !i.ExclusiveMinValue && !i.ExclusiveMaxValue {
+		return describeValues(i, "at least", "at most")
+	}
+
+	list := []string{}
+
+	if i.HasMinValue {
+		if i.ExclusiveMinValue {
+			list = append(list, "greater than "+formatValue(i.MinValue))
+		} else {
+			list = append(list, "at least "+formatValue(i.MinValue))
+		}
+	}
+
+	if i.HasMaxValue {
+		if i.ExclusiveMaxValue {
+			list = append(list, "less than "+formatValue(i.MaxValue))
+		} else {
+			list = append(list, "at most "+formatValue(i.MaxValue))
+		}
+	}
+
+	return strings.Join(list, " and ")
+}
+
+// multipleValue returns a random multiple of the step between lo and hi, near
+// the center value. It returns false if there is no multiple in the range.
+func (g *Generator) multipleValue(lo, hi, center, step float64) (float64, bool) {
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if step <= 0 || first > last {
+		return 0, false
+	}
+
+	k := math.Min(math.Max(math.Round(center/step), first), last)
+	a := math.Max(first, k-generatorSpread)
+	b := math.Min(last, k+generatorSpread)
+
+	// Multiply using the decimal value of the step, so a multiple of 0.1 is
+	// 0.3 rather than 0.30000000000000004.
+	exact, _ := getDecimalValue(step)
+	value, _ := exact.Mul(exact, new(big.Rat).SetFloat64(a+float64(g.rand.Intn(int(b-a)+1)))).Float64()
+
+	return value, true
+}
+
+// intBound returns the smallest or largest int allowed by a minimum or maximum
+// value, which can be a fraction or be outside the range of an int.
+func intBound(limit any, exclusive, lower bool) int {
+	exact, err := getDecimalValue(limit)
+	if err != nil {
+		return 0
+	}
+
+	n := new(big.Int).Quo(exact.Num(), exact.Denom())
+
+	// Move the bound to the next whole number inside the range. The quotient is
+	// rounded toward zero, so it is only inside the range on one side of zero.
+	switch {
+	case lower && (exact.IsInt() && exclusive || !exact.IsInt() && exact.Sign() > 0):
+		n.Add(n, big.NewInt(1))
+
+	case !lower && (exact.IsInt() && exclusive || !exact.IsInt() && exact.Sign() < 0):
+		n.Sub(n, big.NewInt(1))
+	}
+
+	switch {
+	case n.Cmp(big.NewInt(math.MinInt)) < 0:
+		return math.MinInt
+
+	case n.Cmp(big.NewInt(math.MaxInt)) > 0:
+		return math.MaxInt
+	}
+
+	return int(n.Int64())
+}
+
+// numericMutation adds a mutation for the first of the candidate values that
+// causes the expected kind of error for a number.
+func (g *Generator) numericMutation(path []any, rule string, expected error, check func(any) error, candidates ...any) {
+	for _, candidate := range candidates {
+		if err := check(candidate); err != nil && errors.Is(err, expected) {
+			g.mutate(path, candidate, rule, err)
+
+			return
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 )
@@ -64,6 +65,16 @@ func (item *Item) ParseTag(tag string) error {
 			}
 		}
 
+		// The rules for a value apply to the value a pointer points to. Only
+		// the rules for the field itself are kept in the pointer.
+		if item.ItemType == TypePointer && item.BaseType != nil && !isPointerKeyword(key) {
+			if err := item.BaseType.ParseTag(part); err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		// Based on the key, apply the value to the item.
 		switch key {
 		case typeKeyName:
@@ -122,11 +133,14 @@ func (item *Item) ParseTag(tag string) error {
 			item.ItemType = TypeList
 
 		case "name":
-			item.Name = value
+			item.Name = unquote(value)
 
 		case "required":
 			item.Required = true
 
+		case "nullable":
+			item.Nullable = true
+
 		case "minlength", "minlen":
 			item.HasMinLength = true
 
@@ -147,57 +161,277 @@ func (item *Item) ParseTag(tag string) error {
 
 			item.MaxLength = int(n)
 
-		case "maxvalue", "max":
-			item.HasMaxValue = true
+		case "maxvalue", "max", "minvalue", "min":
+			// A limit for a number must be a number. Other types, such as
+			// times and durations, check their limits when they are used.
+			if item.ItemType == TypeInt || item.ItemType == TypeFloat {
+				if _, err := getDecimalValue(unquote(value)); err != nil {
+					return ErrInvalidData.Context(key).Value(value)
+				}
+			}
+
+			if strings.HasPrefix(key, "max") {
+				item.SetMaxValue(unquote(value))
+			} else {
+				item.SetMinValue(unquote(value))
+			}
+
+		case "gt", "lt", "multipleof":
+			if item.ItemType != TypeInt && item.ItemType != TypeFloat {
+				return ErrNotANumber.Context(key).Value(item.ItemType.String())
+			}
+
+			if _, err := getFloatValue(unquote(value)); err != nil {
+				return ErrInvalidData.Context(key).Value(value)
+			}
+
+			switch key {
+			case "gt":
+				item.SetGreaterThan(unquote(value))
+
+			case "lt":
+				item.SetLessThan(unquote(value))
+
+			default:
+				if step, _ := getFloatValue(unquote(value)); step <= 0 {
+					return ErrInvalidData.Context(key).Value(value)
+				}
 
-			item.MaxValue = value
+				item.SetMultipleOf(unquote(value))
+			}
 
-		case "minvalue", "min":
-			item.HasMinValue = true
+		case "decimal":
+			if item.ItemType != TypeInt && item.ItemType != TypeFloat {
+				return ErrNotANumber.Context(key).Value(item.ItemType.String())
+			}
 
-			item.MinValue = value
+			// An integer is always checked exactly, so this only changes a
+			// floating point value.
+			item.SetDecimal(true)
 
 		case "key":
 			if item.ItemType != TypeMap {
 				return ErrNotAMap.Context("key").Value(tag)
 			}
 
-			fallthrough
+			// A list of rules, such as "key=(minlen=2,maxlen=8)", applies to
+			// the key validator. Otherwise, the value is a list of enumerated
+			// values for the keys.
+			if rules, ok := keyRules(value); ok {
+				if item.KeyType == nil {
+					item.KeyType = NewType(TypeString)
+				}
 
-		case "enum", "enums":
-			// Enum can't be used on a bool, struct, or pointer to struct
-			if item.ItemType == TypeBool ||
-				item.ItemType == TypeStruct ||
-				item.ItemType == TypePointer && item.BaseType != nil && item.BaseType.ItemType == TypeStruct {
-				return ErrInvalidEnumType.Context(key).Value(item.ItemType.String())
+				if err := item.KeyType.ParseTag(rules); err != nil {
+					return err
+				}
+
+				break
 			}
 
-			// The values could be separated by "|" characters, or they could be a nested list.
-			sep := "|"
+			fallthrough
 
-			if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
-				value = value[1 : len(value)-1]
-				sep = ","
-			} else if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
-				value = value[1 : len(value)-1]
-				sep = ","
+		case "enum", "enums":
+			// Enum can't be used on a bool or struct. The rules for a pointer
+			// apply to the value it points to, so a pointer to a struct is
+			// checked here as a struct.
+			if item.ItemType == TypeBool || item.ItemType == TypeStruct {
+				return ErrInvalidEnumType.Context(key).Value(item.ItemType.String())
 			}
 
-			// Make the list into an array of values.
-			enums := strings.Split(value, sep)
+			enums := splitList(value)
 			if len(enums) == 0 {
 				return ErrMissingEnumValue.Context(key)
 			}
 
-			item.Enums = make([]string, len(enums))
+			item.Enums = enums
+
+		case "pattern":
+			// The pattern can be wrapped in single quotes, so it can contain
+			// commas.
+			if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) > 1 {
+				value = value[1 : len(value)-1]
+			} else {
+				value = unquote(value)
+			}
 
-			for i, enum := range enums {
-				item.Enums[i] = strings.TrimSpace(enum)
+			if _, err := compilePattern(value); err != nil {
+				return ErrInvalidPattern.Context(key).Value(value).Cause(errors.Unwrap(err))
 			}
 
+			item.Pattern = value
+
 		case "matchcase", "casesensitive":
 			item.CaseSensitive = true
 
+		case "foreignkeys", "allowforeignkeys":
+			item.AllowForeignKey = true
+
+		case "quoted":
+			item.Quoted = true
+
+		case "fieldmatch", "namematch":
+			if _, found := findNormalizer(unquote(value)); !found {
+				return ErrInvalidFieldMatch.Context(key).Value(value)
+			}
+
+			if key == "namematch" {
+				item.NameMatch = unquote(value)
+
+				break
+			}
+
+			// The field matching mode applies to the structure, which may be
+			// the values of an array, map, or pointer.
+			target := item.matchTarget()
+			if target == nil {
+				return ErrInvalidFieldMatch.Context(key).Value(item.ItemType.String())
+			}
+
+			target.FieldMatch = unquote(value)
+
+		case RuleGreaterField, RuleGreaterEqualField, RuleLessField, RuleLessEqualField, RuleEqualField, RuleNotEqualField:
+			if value == "" {
+				return ErrEmptyTagValue.Context(key)
+			}
+
+			item.FieldRules = append(item.FieldRules, FieldRule{Op: key, Field: unquote(value)})
+
+		case RuleRequiredIf, RuleRequiredWith, RuleRequiredWithout, RuleExcludedIf:
+			condition, err := parseCondition(key, value)
+			if err != nil {
+				return err
+			}
+
+			item.Conditions = append(item.Conditions, condition)
+
+		case GroupOneOf, GroupAnyOf, GroupAllOf, GroupNoneOf:
+			// The group applies to the structure, which may be the values of
+			// an array, map, or pointer.
+			target := item.matchTarget()
+			if target == nil {
+				return ErrInvalidGroup.Context(key).Value(item.ItemType.String())
+			}
+
+			target.Groups = append(target.Groups, FieldGroup{Op: key, Fields: splitList(value)})
+
+			if err := target.verifyGroups(); err != nil {
+				return err
+			}
+
+		case "unique", "unique_by", "contains":
+			if item.ItemType != TypeArray {
+				return ErrNotAnArray.Context(key).Value(item.ItemType.String())
+			}
+
+			switch key {
+			case "unique":
+				item.Unique = true
+
+			case "unique_by":
+				item.UniqueBy = unquote(value)
+
+			default:
+				// The values are written as a type in the definition language,
+				// such as "contains=(string: enum=admin)".
+				v, err := Compile(trimGroup(value))
+				if err != nil {
+					return err
+				}
+
+				item.Contains = v
+			}
+
+		case "mincontains", "maxcontains":
+			if item.ItemType != TypeArray {
+				return ErrNotAnArray.Context(key).Value(item.ItemType.String())
+			}
+
+			n, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || n < 0 {
+				return ErrInvalidInteger.Context(key).Value(value)
+			}
+
+			if key == "mincontains" {
+				item.SetMinContains(int(n))
+			} else {
+				item.SetMaxContains(int(n))
+			}
+
+		case "additional":
+			// The values are written as a type in the definition language,
+			// such as "additional=(string: maxlen=64)". For a tuple, this is
+			// the type of the values after the positions of the tuple.
+			target := item.matchTarget()
+			if target == nil && item.ItemType != TypeTuple {
+				return ErrNotAStruct.Context(key).Value(item.ItemType.String())
+			}
+
+			v, err := Compile(trimGroup(value))
+			if err != nil {
+				return err
+			}
+
+			if item.ItemType == TypeTuple {
+				item.AdditionalItems = v
+			} else {
+				target.AdditionalProperties = v
+			}
+
+		case "property":
+			target := item.matchTarget()
+			if target == nil {
+				return ErrNotAStruct.Context(key).Value(item.ItemType.String())
+			}
+
+			property, err := parseProperty(value)
+			if err != nil {
+				return err
+			}
+
+			target.PatternProperties = append(target.PatternProperties, property)
+
+		case "discriminator":
+			if item.ItemType != TypeUnion {
+				return ErrNotAUnion.Context(key).Value(item.ItemType.String())
+			}
+
+			item.Discriminator = unquote(value)
+
+			// Each variant must have a different discriminator value.
+			if err := item.verifyUnion(); err != nil {
+				return err
+			}
+
+		case "mode":
+			if item.ItemType != TypeUnion {
+				return ErrNotAUnion.Context(key).Value(item.ItemType.String())
+			}
+
+			if value != UnionOneOf && value != UnionAnyOf {
+				return ErrInvalidKeyword.Context(key).Value(value).Expected(UnionOneOf, UnionAnyOf)
+			}
+
+			item.UnionMode = value
+
+		case "rule":
+			// The expression is normally wrapped in single quotes, so it can
+			// contain commas and double-quoted strings.
+			if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) > 1 {
+				value = value[1 : len(value)-1]
+			} else {
+				value = unquote(value)
+			}
+
+			if err := item.AddRule(value); err != nil {
+				return err
+			}
+
+			// The expression must name fields of the structure.
+			if err := item.matchTarget().compileRules(); err != nil {
+				return err
+			}
+
 		default:
 			return ErrInvalidKeyword.Value(key)
 		}
@@ -206,6 +440,66 @@ func (item *Item) ParseTag(tag string) error {
 	return err
 }
 
+// isPointerKeyword reports if a tag keyword is a rule for a field itself, rather
+// than for its value. These rules are kept in a pointer validator, and the other
+// rules apply to the value the pointer points to.
+func isPointerKeyword(key string) bool {
+	switch key {
+	case typeKeyName, "name", "required", "nullable", "quoted", "namematch", "base", "value":
+		return true
+	}
+
+	return isFieldRule(key) || isCondition(key)
+}
+
+// keyRules returns the rules for the keys of a map, if the value of a "key" tag
+// is a list of rules wrapped in parentheses or single quotes rather than a list
+// of enumerated values.
+func keyRules(value string) (string, bool) {
+	if len(value) < 2 || !strings.Contains(value, "=") {
+		return "", false
+	}
+
+	if (strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")) ||
+		(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
+		return value[1 : len(value)-1], true
+	}
+
+	return "", false
+}
+
+// splitList splits the value of a keyword that is a list, such as a list of
+// enumerated values. The values can be separated by "|" characters, or they can
+// be a list separated by commas inside parentheses or single quotes. Quoted values
+// can contain separators, and each value is unquoted.
+func splitList(value string) []string {
+	sep := "|"
+
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		value = value[1 : len(value)-1]
+		sep = ","
+	} else if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
+		value = value[1 : len(value)-1]
+		sep = ","
+	}
+
+	// Make the list into an array of values. If any of the values are
+	// quoted, the separators inside the quotes are not used to split
+	// the list.
+	var list []string
+	if strings.Contains(value, "\"") {
+		list = Split(value, sep)
+	} else {
+		list = strings.Split(value, sep)
+	}
+
+	for n, element := range list {
+		list[n] = unquote(strings.TrimSpace(element))
+	}
+
+	return list
+}
+
 // Split a string into separate components, using a defined separator character.
 // If the separator is not provided, the function defaults to a comma ",".  The
 // split ignores separators enclosed within single quotes or parentheses.
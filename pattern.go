@@ -0,0 +1,294 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"sync"
+)
+
+// The compiled regular expressions for the patterns used by validators. They
+// are stored by the text of the pattern, and access to the map is serialized
+// by a mutex.
+var patterns = map[string]*regexp.Regexp{}
+
+var patternLock sync.Mutex
+
+// The largest number of times a repeated element of a pattern is repeated in
+// a generated value, beyond the minimum number of times.
+const patternRepeat = 3
+
+// The pattern for the standard base64 encoding with padding, which is how
+// encoding/json writes a slice of bytes.
+const base64Pattern = `^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$`
+
+// SetPattern sets the regular expression that a string value must match. For a
+// map, the pattern applies to the keys of the map. The expression is not anchored,
+// so use "^" and "$" to match the whole value. An invalid expression is reported
+// when a value is validated.
+func (i *Item) SetPattern(pattern string) *Item {
+	if i == nil {
+		return nil
+	}
+
+	i.Pattern = pattern
+
+	return i
+}
+
+// SetKeyType sets the validator for the keys of a map. The key validator can be a
+// string, integer, UUID, time, or custom type validator, and the text of each key
+// must be valid for it. If the item is not a map, no change is made to the item.
+func (i *Item) SetKeyType(key *Item) *Item {
+	if i == nil || i.ItemType != TypeMap {
+		return i
+	}
+
+	i.KeyType = key
+
+	return i
+}
+
+// compilePattern returns the compiled regular expression for a pattern. Each
+// pattern is only compiled once.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patternLock.Lock()
+	defer patternLock.Unlock()
+
+	if re, found := patterns[pattern]; found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, ErrInvalidPattern.Value(pattern).Cause(err)
+	}
+
+	patterns[pattern] = re
+
+	return re, nil
+}
+
+// validatePattern reports an error if the value does not match the pattern of
+// the item.
+func (i *Item) validatePattern(value string) error {
+	if i.Pattern == "" {
+		return nil
+	}
+
+	re, err := compilePattern(i.Pattern)
+	if err != nil {
+		return ErrInvalidPattern.Context(i.Name).Value(i.Pattern).Cause(errors.Unwrap(err))
+	}
+
+	if !re.MatchString(value) {
+		return ErrPatternMismatch.Context(i.Name).Value(value).Expected(i.Pattern)
+	}
+
+	return nil
+}
+
+// validateKey checks a key of a map against the enumerated values and pattern
+// of the map, and against the key validator if there is one. An error from the
+// key validator is reported in the context of the map.
+func (i *Item) validateKey(key string, depth int) error {
+	if len(i.Enums) > 0 && !i.isEnum(key) {
+		return ErrInvalidEnumeratedValue.Context(i.Name).Value(key).Expected(i.Enums)
+	}
+
+	if err := i.validatePattern(key); err != nil {
+		return err
+	}
+
+	if i.KeyType != nil {
+		value, err := i.KeyType.keyValue(key)
+		if err == nil {
+			err = i.KeyType.validateValue(value, depth+1)
+		}
+
+		if err != nil {
+			return i.contextError(err)
+		}
+	}
+
+	return nil
+}
+
+// keyValue converts the text of a map key to the value checked by the key
+// validator. Like encoding/json, integer keys are written as decimal text, and
+// keys of other types are the text of the value. An integer key is read as an
+// exact integer, so the key validator checks the bounds of a key of any size,
+// such as the largest uint64.
+func (i *Item) keyValue(key string) (any, error) {
+	if i.ItemType != TypeInt {
+		return key, nil
+	}
+
+	n, err := getIntegerValue(key)
+	if err != nil {
+		return nil, ErrInvalidData.Context(i.Name).Value(key)
+	}
+
+	return json.Number(n.String()), nil
+}
+
+// isKeyType reports if a map key can have the given type. JSON object keys are
+// always strings, so the key must be a type that can be written as text.
+func isKeyType(kind Type) bool {
+	switch kind {
+	case TypeString, TypeInt, TypeUUID, TypeTime, TypeCustom:
+		return true
+	}
+
+	return false
+}
+
+// contextError returns an error from the validator of a map key. If the error
+// does not name the item it applies to, the name of the map is used.
+func (i *Item) contextError(err error) error {
+	var e *ValidationError
+
+	if errors.As(err, &e) && e.context == "" {
+		return e.Context(i.Name)
+	}
+
+	return err
+}
+
+// patternValue returns a random string that matches the pattern of the item, with
+// a length in the given range. If the item has no length limits, the range is not
+// used.
+func (g *Generator) patternValue(i *Item, lo, hi int) (string, error) {
+	re, err := compilePattern(i.Pattern)
+	if err != nil {
+		return "", ErrCannotGenerate.Context(i.Name).Value("invalid pattern")
+	}
+
+	tree, err := syntax.Parse(i.Pattern, syntax.Perl)
+	if err != nil {
+		return "", ErrCannotGenerate.Context(i.Name).Value("invalid pattern")
+	}
+
+	tree = tree.Simplify()
+
+	for range generatorAttempts {
+		var b strings.Builder
+
+		g.writePattern(&b, tree)
+
+		value := b.String()
+		if (i.HasMinLength && len(value) < lo) || (i.HasMaxLength && len(value) > hi) {
+			continue
+		}
+
+		if re.MatchString(value) {
+			return value, nil
+		}
+	}
+
+	return "", ErrCannotGenerate.Context(i.Name).Value("no value matches the pattern")
+}
+
+// writePattern writes a random string that matches the parsed regular expression.
+// Letters and digits are preferred when a character class allows them, so the
+// generated values are easy to read.
+func (g *Generator) writePattern(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+
+	case syntax.OpCharClass:
+		b.WriteRune(g.classRune(re.Rune))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(byte('a' + g.rand.Intn(26)))
+
+	case syntax.OpCapture:
+		g.writePattern(b, re.Sub[0])
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.writePattern(b, sub)
+		}
+
+	case syntax.OpAlternate:
+		g.writePattern(b, re.Sub[g.rand.Intn(len(re.Sub))])
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := 0, patternRepeat
+
+		switch re.Op {
+		case syntax.OpPlus:
+			lo = 1
+		case syntax.OpQuest:
+			hi = 1
+		case syntax.OpRepeat:
+			lo, hi = re.Min, re.Max
+			if hi < 0 || hi > lo+patternRepeat {
+				hi = lo + patternRepeat
+			}
+		}
+
+		for range lo + g.rand.Intn(hi-lo+1) {
+			g.writePattern(b, re.Sub[0])
+		}
+	}
+}
+
+// classRune returns a random character from a character class, which is a list
+// of pairs of the first and last characters of each range in the class.
+func (g *Generator) classRune(ranges []rune) rune {
+	if len(ranges) == 0 {
+		return 'a'
+	}
+
+	// Try a few letters and digits, and use any that is in the class.
+	const readable = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	for range 20 {
+		r := rune(readable[g.rand.Intn(len(readable))])
+
+		for n := 0; n+1 < len(ranges); n += 2 {
+			if r >= ranges[n] && r <= ranges[n+1] {
+				return r
+			}
+		}
+	}
+
+	n := g.rand.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[n], ranges[n+1]
+
+	// Avoid the control characters at the start of a negated class.
+	if lo < ' ' && hi >= ' ' {
+		lo = ' '
+	}
+
+	return lo + rune(g.rand.Intn(int(min(hi-lo, 94))+1))
+}
+
+// nonPattern returns a word with a length in the given range that does not match
+// the pattern of the item.
+func (g *Generator) nonPattern(i *Item, lo, hi int) (string, bool) {
+	re, err := compilePattern(i.Pattern)
+	if err != nil || lo > hi {
+		return "", false
+	}
+
+	for attempt := range 20 {
+		word := g.word(lo, hi)
+
+		// Some of the attempts use other characters, for patterns that accept
+		// any letters.
+		if attempt%2 == 1 && len(word) > 0 {
+			word = word[:len(word)-1] + "#"
+		}
+
+		if !re.MatchString(word) {
+			return word, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,270 @@
+package validator
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatternProperty is a rule for the keys of a JSON object that are not fields
+// of the structure. The value of each such key that matches the pattern must
+// be valid for the value validator, like the "patternProperties" keyword of a
+// JSON Schema.
+type PatternProperty struct {
+	// The regular expression the key must match. Like other patterns, the
+	// expression is not anchored.
+	Pattern string `json:"pattern"`
+
+	// The validator for the value of each matching key. If this is nil, any
+	// value is accepted.
+	Value *Item `json:"value,omitempty"`
+}
+
+// SetAdditionalProperties sets the validator for the value of each key of a JSON
+// object that is not a field of the structure, and does not match the pattern of
+// a pattern property. When this is set, these keys are allowed even if foreign keys
+// are not. If the item is not a structure, no change is made to the item.
+func (i *Item) SetAdditionalProperties(v *Item) *Item {
+	if i == nil || i.ItemType != TypeStruct {
+		return i
+	}
+
+	i.AdditionalProperties = v
+
+	return i
+}
+
+// AddPatternProperty adds a validator for the value of each key of a JSON object
+// that is not a field of the structure and matches the pattern. A key that matches
+// more than one pattern must be valid for each of them. An invalid pattern is
+// reported when a value is validated. If the item is not a structure, no change is
+// made to the item.
+func (i *Item) AddPatternProperty(pattern string, v *Item) *Item {
+	if i == nil || i.ItemType != TypeStruct {
+		return i
+	}
+
+	i.PatternProperties = append(i.PatternProperties, PatternProperty{Pattern: pattern, Value: v})
+
+	return i
+}
+
+// properties returns the validators for the value of a key that is not a field
+// of the structure. These are the validators of each pattern property that the
+// key matches or, if there are none, the validator for additional properties. An
+// empty list means the key is a foreign key. A nil validator accepts any value.
+func (i *Item) properties(key string) ([]*Item, error) {
+	var result []*Item
+
+	for _, property := range i.PatternProperties {
+		re, err := compilePattern(property.Pattern)
+		if err != nil {
+			return nil, ErrInvalidPattern.Context(i.Name).Value(property.Pattern).Cause(errors.Unwrap(err))
+		}
+
+		if re.MatchString(key) {
+			result = append(result, property.Value)
+		}
+	}
+
+	if len(result) == 0 && i.AdditionalProperties != nil {
+		result = append(result, i.AdditionalProperties)
+	}
+
+	return result, nil
+}
+
+// validateProperties checks the value of each key of the JSON object that is not
+// a field of the structure, using the pattern properties and additional property
+// validator of the structure. The keys are checked in sorted order, so the error
+// reported for an object with more than one invalid value is always the same.
+func (i *Item) validateProperties(m map[string]any, keys map[*Item]string, depth int) error {
+	if len(i.PatternProperties) == 0 && i.AdditionalProperties == nil {
+		return nil
+	}
+
+	fields := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		fields[key] = true
+	}
+
+	names := make([]string, 0, len(m))
+
+	for key := range m {
+		if !fields[key] {
+			names = append(names, key)
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, key := range names {
+		items, err := i.properties(key)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+
+			if err := item.validateValue(m[key], depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseProperty parses the value of a "property" tag, which is a quoted pattern
+// followed by the type of the values, written in the definition language, as in
+// `property=("^x-" string: maxlen=64)`. If there is no type, any value is valid.
+func parseProperty(value string) (PatternProperty, error) {
+	text := strings.TrimSpace(trimGroup(value))
+
+	var (
+		pattern string
+		rest    string
+	)
+
+	switch {
+	case strings.HasPrefix(text, "'"):
+		end := strings.Index(text[1:], "'")
+		if end < 0 {
+			return PatternProperty{}, ErrInvalidPattern.Context("property").Value(value)
+		}
+
+		pattern, rest = text[1:end+1], text[end+2:]
+
+	case strings.HasPrefix(text, "\""):
+		quoted, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return PatternProperty{}, ErrInvalidPattern.Context("property").Value(value)
+		}
+
+		pattern, rest = unquote(quoted), text[len(quoted):]
+
+	default:
+		return PatternProperty{}, ErrInvalidPattern.Context("property").Value(value)
+	}
+
+	if _, err := compilePattern(pattern); err != nil {
+		return PatternProperty{}, ErrInvalidPattern.Context("property").Value(pattern).Cause(errors.Unwrap(err))
+	}
+
+	property := PatternProperty{Pattern: pattern}
+
+	if rest = strings.TrimSpace(rest); rest != "" {
+		item, err := Compile(rest)
+		if err != nil {
+			return PatternProperty{}, err
+		}
+
+		property.Value = item
+	}
+
+	return property, nil
+}
+
+// trimGroup removes the parentheses or single quotes that wrap the value of a
+// tag keyword, if there are any.
+func trimGroup(value string) string {
+	if len(value) < 2 {
+		return value
+	}
+
+	if (strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")) ||
+		(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// propertyFields adds values to a generated structure for the keys that are not
+// fields of the structure. A key is generated for each pattern property, and for
+// the additional property validator, and each key is added half the time, unless
+// the mutations of its value are being collected.
+func (g *Generator) propertyFields(i *Item, path []any, depth int, collect bool, result map[string]any) error {
+	if len(i.PatternProperties) == 0 && i.AdditionalProperties == nil {
+		return nil
+	}
+
+	keys := map[string]*Item{}
+	names := []string{}
+
+	// A key is only used for a pattern property if no other pattern matches
+	// it, so its value only needs to be valid for one validator.
+	for _, property := range i.PatternProperties {
+		key, err := g.patternValue(&Item{Name: i.Name, Pattern: property.Pattern}, 0, 0)
+		if err != nil {
+			return err
+		}
+
+		if _, found := keys[key]; !found && i.isPropertyKey(key, result) && i.patternCount(key) == 1 {
+			keys[key] = property.Value
+			names = append(names, key)
+		}
+	}
+
+	if i.AdditionalProperties != nil {
+		key := "extra"
+
+		for n := 1; n <= generatorAttempts; n++ {
+			if i.isPropertyKey(key, result) && i.patternCount(key) == 0 {
+				keys[key] = i.AdditionalProperties
+				names = append(names, key)
+
+				break
+			}
+
+			key = "extra" + strconv.Itoa(n)
+		}
+	}
+
+	for _, key := range names {
+		if !collect && (depth >= g.MaxDepth || g.rand.Intn(2) == 0) {
+			continue
+		}
+
+		v := keys[key]
+		if v == nil {
+			v = NewType(TypeAny)
+		}
+
+		value, err := g.value(v, appendPath(path, key), depth+1, collect)
+		if err != nil {
+			return err
+		}
+
+		result[key] = value
+	}
+
+	return nil
+}
+
+// isPropertyKey reports if a key can be added to a generated structure for a
+// pattern property or additional property. The key must not already be used, and
+// must not match a field of the structure.
+func (i *Item) isPropertyKey(key string, result map[string]any) bool {
+	_, found := result[key]
+
+	return !found && !i.hasField(key)
+}
+
+// patternCount returns the number of pattern properties whose pattern matches
+// the key. A pattern that is not valid does not match any key.
+func (i *Item) patternCount(key string) int {
+	count := 0
+
+	for _, property := range i.PatternProperties {
+		if re, err := compilePattern(property.Pattern); err == nil && re.MatchString(key) {
+			count++
+		}
+	}
+
+	return count
+}
@@ -23,6 +23,22 @@ func New(v any) (*Item, error) {
 	return defineItem(v, 0)
 }
 
+// defineType defines a validator for a Go type, using the zero value of the type.
+// The zero value of an interface type is nil, so an interface type that has
+// registered implementations is found by the type instead, and is a union of
+// the implementations. Like any interface, it can be null.
+func defineType(t reflect.Type, depth int) (*Item, error) {
+	if t.Kind() == reflect.Interface {
+		if union, found := findUnion(t); found {
+			union.Nullable = true
+
+			return union, nil
+		}
+	}
+
+	return defineItem(reflect.Zero(t).Interface(), depth)
+}
+
 // defineItem handles defining a new validator for the given value. It can call itself
 // recursively to a maximum allowed depth to handle nested structures, arrays of
 // structures, etc.
@@ -46,6 +62,14 @@ func defineItem(v any, depth int) (*Item, error) {
 
 	kind := valueType.Kind()
 
+	// Types with a registered handler are validated using the handler.
+	if handler, found := findTypeHandler(valueType); found {
+		item.ItemType = TypeCustom
+		item.TypeName = handler.Name()
+
+		return item, nil
+	}
+
 	// Handle well-known external types first. We have accessors, formatters, and
 	// validators for these types even though they are external types, because they
 	// are common value types found in JSON.
@@ -64,6 +88,26 @@ func defineItem(v any, depth int) (*Item, error) {
 	case "time.Duration":
 		item.ItemType = TypeDuration
 
+		return item, nil
+
+	case "json.Number":
+		// A json.Number keeps the exact text of the number, so it is checked
+		// as an exact decimal number.
+		item.ItemType = TypeFloat
+		item.Decimal = true
+
+		return item, nil
+	}
+
+	// Types that decode their own JSON value are validated by decoding the
+	// value with the type's own methods.
+	if isUnmarshaler(valueType) {
+		name := customTypeName(valueType)
+		storeCustomType(name, customType{goType: valueType})
+
+		item.ItemType = TypeCustom
+		item.TypeName = name
+
 		return item, nil
 	}
 
@@ -71,25 +115,29 @@ func defineItem(v any, depth int) (*Item, error) {
 	switch kind {
 	case reflect.Interface:
 		item.ItemType = TypeAny
+		item.Nullable = true
 
 	case reflect.Pointer:
 		// Dereference the pointer and create an item for the base type
-		valueType = valueType.Elem()
-		v = reflect.Zero(valueType).Interface()
 		item.ItemType = TypePointer
+		item.Nullable = true
 
-		item.BaseType, err = New(v)
+		item.BaseType, err = defineType(valueType.Elem(), 0)
 		if err != nil {
 			return nil, err
 		}
 
 	case reflect.Map:
+		// A nil map is written as null, so the map can be null.
 		item.ItemType = TypeMap
+		item.Nullable = true
 		// Determine the type of the map's value type
-		elemType := valueType.Elem()
-		v = reflect.Zero(elemType).Interface()
+		item.BaseType, err = defineType(valueType.Elem(), 0)
+		if err != nil {
+			return nil, err
+		}
 
-		item.BaseType, err = New(v)
+		item.KeyType, err = mapKeyType(valueType.Key())
 		if err != nil {
 			return nil, err
 		}
@@ -112,6 +160,11 @@ func defineItem(v any, depth int) (*Item, error) {
 		item.SetMinValue(0)
 		item.SetMaxValue(math.MaxUint32)
 
+	case reflect.Uint, reflect.Uint64:
+		item.ItemType = TypeInt
+		item.SetMinValue(0)
+		item.SetMaxValue(uint64(math.MaxUint64))
+
 	case reflect.Int8:
 		item.ItemType = TypeInt
 		item.SetMinValue(math.MinInt8)
@@ -146,8 +199,17 @@ func defineItem(v any, depth int) (*Item, error) {
 		item.ItemType = TypeBool
 
 	case reflect.Array, reflect.Slice:
+		// Like encoding/json, a slice of bytes is written as a base64 string.
+		if isByteSlice(valueType) {
+			item.ItemType = TypeString
+			item.Pattern = base64Pattern
+			item.Nullable = true
+
+			return item, nil
+		}
+
 		// Create an item for the base type of the array/slice
-		baseItem, err := New(reflect.Zero(valueType.Elem()).Interface())
+		baseItem, err := defineType(valueType.Elem(), 0)
 		if err != nil {
 			return nil, err
 		}
@@ -155,6 +217,18 @@ func defineItem(v any, depth int) (*Item, error) {
 		item.ItemType = TypeArray
 		item.BaseType = baseItem
 
+		// A nil slice is written as null, so the slice can be null.
+		if kind == reflect.Slice {
+			item.Nullable = true
+		}
+
+		// A Go array has a fixed length, so the JSON array must have the
+		// same number of values.
+		if kind == reflect.Array {
+			item.SetMinLength(valueType.Len())
+			item.SetMaxLength(valueType.Len())
+		}
+
 	case reflect.Struct:
 		var cacheThis bool
 
@@ -182,27 +256,27 @@ func defineItem(v any, depth int) (*Item, error) {
 				Alias:    typeName})
 		}
 
-		for i := 0; i < valueType.NumField(); i++ {
-			field := valueType.Field(i)
-
-			fieldItem, err := defineItem(reflect.Zero(field.Type).Interface(), depth+1)
+		// Use the same fields that encoding/json would use for this structure.
+		for _, field := range typeFields(valueType) {
+			fieldItem, err := defineType(field.field.Type, depth+1)
 			if err != nil {
 				return nil, err
 			}
 
-			fieldItem.Name = field.Name
+			fieldItem.Name = field.name
+			fieldItem.Quoted = field.quoted
 
-			// See if there is a JSON tag to get the field name from
-			jsonTag := field.Tag.Get("json")
-			if len(jsonTag) > 0 {
-				jsonParts := strings.SplitN(jsonTag, ",", 2)
-				if len(jsonParts) > 0 && jsonParts[0] != "" {
-					fieldItem.Name = jsonParts[0]
+			// If the field was promoted from an embedded structure, the validate
+			// tags of the embedded fields apply to it first, so the field's own
+			// validate tag can override them.
+			for _, rule := range field.rules {
+				if err = fieldItem.ParseTag(rule); err != nil {
+					return nil, err
 				}
 			}
 
 			// Parse the field's validate tag if present and build an item for it
-			tagString := field.Tag.Get(validateTagName)
+			tagString := field.field.Tag.Get(validateTagName)
 			if len(strings.TrimSpace(tagString)) > 0 {
 				err = fieldItem.ParseTag(tagString)
 				if err != nil {
@@ -213,6 +287,31 @@ func defineItem(v any, depth int) (*Item, error) {
 			item.Fields = append(item.Fields, fieldItem)
 		}
 
+		if err := item.verifyFieldRules(); err != nil {
+			return nil, err
+		}
+
+		// The validate tag of a field named "_" applies to the structure itself,
+		// which is how field groups are declared.
+		for n := 0; n < valueType.NumField(); n++ {
+			if field := valueType.Field(n); field.Name == "_" {
+				if tag := field.Tag.Get(validateTagName); strings.TrimSpace(tag) != "" {
+					if err := item.ParseTag(tag); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+
+		// If the structure type checks its own values, record the type so
+		// its methods can be called after the fields are validated.
+		if valueType.Name() != "" && isSelfValidating(valueType) {
+			name := customTypeName(valueType)
+			storeValidatorType(name, valueType)
+
+			item.TypeName = name
+		}
+
 		if cacheThis {
 			store(aliasPrefix+typeName, item)
 		}
@@ -223,3 +322,50 @@ func defineItem(v any, depth int) (*Item, error) {
 
 	return item, err
 }
+
+// mapKeyType returns the validator for the keys of a map with the given key type.
+// Like encoding/json, the keys can be strings, integers, or types that implement
+// encoding.TextUnmarshaler. No validator is needed for string keys.
+func mapKeyType(t reflect.Type) (*Item, error) {
+	_, found := findTypeHandler(t)
+
+	if found || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return New(reflect.Zero(t).Interface())
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return nil, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		key, err := New(reflect.Zero(t).Interface())
+		if err != nil {
+			return nil, err
+		}
+
+		// An integer type such as time.Duration is written as an integer.
+		key.ItemType = TypeInt
+
+		return key, nil
+	}
+
+	return nil, ErrUnsupportedType.Context("key").Value(t.String())
+}
+
+// isByteSlice reports if a type is a slice of bytes that encoding/json writes
+// as a base64 string. A slice whose type or element type has its own JSON or
+// text marshaler is written by that marshaler instead.
+func isByteSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uint8 {
+		return false
+	}
+
+	for _, m := range []reflect.Type{t, reflect.PointerTo(t), reflect.PointerTo(t.Elem())} {
+		if m.Implements(jsonMarshalerType) || m.Implements(textMarshalerType) {
+			return false
+		}
+	}
+
+	return true
+}
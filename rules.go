@@ -0,0 +1,429 @@
+package validator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// The cross-field comparison rules. Each compares the value of a field with the
+// value of another field in the same structure.
+const (
+	// The value must be greater than the other field.
+	RuleGreaterField = "gtfield"
+
+	// The value must be greater than or equal to the other field.
+	RuleGreaterEqualField = "gtefield"
+
+	// The value must be less than the other field.
+	RuleLessField = "ltfield"
+
+	// The value must be less than or equal to the other field.
+	RuleLessEqualField = "ltefield"
+
+	// The value must be equal to the other field.
+	RuleEqualField = "eqfield"
+
+	// The value must not be equal to the other field.
+	RuleNotEqualField = "nefield"
+)
+
+// The conditional presence rules. Each decides if a field must be present, or
+// must not be present, based on another field in the same structure.
+const (
+	// The field is required when the other field has the given value.
+	RuleRequiredIf = "required_if"
+
+	// The field is required when the other field is present.
+	RuleRequiredWith = "required_with"
+
+	// The field is required when the other field is not present.
+	RuleRequiredWithout = "required_without"
+
+	// The field must not be present when the other field has the given value.
+	RuleExcludedIf = "excluded_if"
+)
+
+// FieldRule is a rule that compares the value of a field with the value of
+// another field in the same structure, such as an end date that must be after
+// a start date. The rule is only checked when both fields are present and not
+// null in the JSON object.
+type FieldRule struct {
+	// The comparison, such as "gtfield" or "eqfield".
+	Op string `json:"op"`
+
+	// The name of the other field.
+	Field string `json:"field"`
+}
+
+// Condition is a rule that decides if a field must be present, or must not be
+// present, based on another field in the same structure, such as a card number
+// that is required when the payment type is "card". A field is present when its
+// key is in the JSON object. The other field is present when its key is in the
+// JSON object and its value is not null.
+type Condition struct {
+	// The rule, such as "required_if" or "required_with".
+	Op string `json:"op"`
+
+	// The name of the other field.
+	Field string `json:"field"`
+
+	// For the required_if and excluded_if rules, the value of the other field
+	// that the rule applies to, compared as text.
+	Value string `json:"value,omitempty"`
+}
+
+// String returns the condition as it is written in a validate tag.
+func (c Condition) String() string {
+	if c.Op == RuleRequiredIf || c.Op == RuleExcludedIf {
+		return c.Op + "=" + c.Field + ":" + c.Value
+	}
+
+	return c.Op + "=" + c.Field
+}
+
+// isFieldRule reports if the keyword is one of the cross-field comparison rules.
+func isFieldRule(op string) bool {
+	switch op {
+	case RuleGreaterField, RuleGreaterEqualField, RuleLessField, RuleLessEqualField, RuleEqualField, RuleNotEqualField:
+		return true
+	}
+
+	return false
+}
+
+// AddFieldRule adds a rule that compares the value of this field with the value
+// of another field in the same structure. The op is one of the cross-field
+// comparison rules, such as RuleGreaterField. Integers, floating point values,
+// times, durations, strings, and custom types with a registered handler can be
+// compared in order; other values can only be compared for equality. If the op
+// is not a cross-field comparison rule, no change is made to the item.
+func (i *Item) AddFieldRule(op, field string) *Item {
+	if i == nil || !isFieldRule(op) {
+		return i
+	}
+
+	i.FieldRules = append(i.FieldRules, FieldRule{Op: op, Field: field})
+
+	return i
+}
+
+// AddCondition adds a rule that decides if this field must be present, based on
+// another field in the same structure. The op is one of the conditional presence
+// rules, such as RuleRequiredIf. The value is only used by the RuleRequiredIf and
+// RuleExcludedIf rules. If the op is not a conditional presence rule, no change is
+// made to the item.
+func (i *Item) AddCondition(op, field, value string) *Item {
+	if i == nil || !isCondition(op) {
+		return i
+	}
+
+	i.Conditions = append(i.Conditions, Condition{Op: op, Field: field, Value: value})
+
+	return i
+}
+
+// isCondition reports if the keyword is one of the conditional presence rules.
+func isCondition(op string) bool {
+	switch op {
+	case RuleRequiredIf, RuleRequiredWith, RuleRequiredWithout, RuleExcludedIf:
+		return true
+	}
+
+	return false
+}
+
+// parseCondition creates a condition from a validate tag keyword and its value.
+// The required_if and excluded_if rules have a value of the form "field:value".
+// Either part can be a quoted string.
+func parseCondition(op, text string) (Condition, error) {
+	if op != RuleRequiredIf && op != RuleExcludedIf {
+		return Condition{Op: op, Field: unquote(text)}, nil
+	}
+
+	field, value, found := strings.Cut(text, ":")
+
+	if strings.HasPrefix(text, "\"") {
+		prefix, err := strconv.QuotedPrefix(text)
+		if err != nil {
+			return Condition{}, ErrInvalidCondition.Context(op).Value(text)
+		}
+
+		field, value, found = prefix, strings.TrimPrefix(text[len(prefix):], ":"), strings.HasPrefix(text[len(prefix):], ":")
+	}
+
+	if !found || strings.TrimSpace(field) == "" {
+		return Condition{}, ErrInvalidCondition.Context(op).Value(text)
+	}
+
+	return Condition{Op: op, Field: unquote(strings.TrimSpace(field)), Value: unquote(strings.TrimSpace(value))}, nil
+}
+
+// verifyFieldRules reports an error if a field of the structure has a rule
+// that refers to a field that does not exist in the structure.
+func (i *Item) verifyFieldRules() error {
+	for _, field := range i.Fields {
+		for _, condition := range field.Conditions {
+			if !isCondition(condition.Op) {
+				return ErrInvalidKeyword.Context(field.Name).Value(condition.Op)
+			}
+
+			if i.exactField(condition.Field) == nil {
+				return ErrInvalidFieldName.Context(field.Name).Value(condition.Field)
+			}
+		}
+
+		for _, rule := range field.FieldRules {
+			if !isFieldRule(rule.Op) {
+				return ErrInvalidKeyword.Context(field.Name).Value(rule.Op)
+			}
+
+			if i.exactField(rule.Field) == nil {
+				return ErrInvalidFieldName.Context(field.Name).Value(rule.Field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// failedCondition returns the first conditional presence rule of the field that
+// is not satisfied by the JSON object, using the key found for each field.
+func (i *Item) failedCondition(field *Item, m map[string]any, keys map[*Item]string) (Condition, bool) {
+	_, present := keys[field]
+
+	for _, condition := range field.Conditions {
+		var value any
+
+		other := i.exactField(condition.Field)
+		otherKey, otherPresent := keys[other]
+
+		if otherPresent {
+			value = m[otherKey]
+			otherPresent = value != nil
+		}
+
+		if otherPresent && other.Quoted {
+			value, _ = other.quotedValue(value)
+		}
+
+		switch condition.Op {
+		case RuleRequiredIf:
+			if !present && otherPresent && conditionText(value) == condition.Value {
+				return condition, true
+			}
+
+		case RuleRequiredWith:
+			if !present && otherPresent {
+				return condition, true
+			}
+
+		case RuleRequiredWithout:
+			if !present && !otherPresent {
+				return condition, true
+			}
+
+		case RuleExcludedIf:
+			if present && otherPresent && conditionText(value) == condition.Value {
+				return condition, true
+			}
+		}
+	}
+
+	return Condition{}, false
+}
+
+// conditionError returns the error reported when a field does not satisfy a
+// conditional presence rule.
+func conditionError(field *Item, condition Condition) error {
+	if condition.Op == RuleExcludedIf {
+		return ErrExcluded.Value(field.Name).Expected(condition.String())
+	}
+
+	return ErrRequired.Value(field.Name).Expected(condition.String())
+}
+
+// conditionText returns the text of a JSON value, used to compare it with the
+// value of a conditional presence rule.
+func conditionText(v any) string {
+	switch actual := v.(type) {
+	case string:
+		return actual
+
+	case float64:
+		return strconv.FormatFloat(actual, 'f', -1, 64)
+
+	case json.Number:
+		return numberText(actual)
+	}
+
+	return fmt.Sprintf("%v", v)
+}
+
+// validateFieldRules checks the cross-field comparison rules of each field of
+// the structure, using the key found for each field in the JSON object. A rule
+// is skipped if either field is missing or null.
+func (i *Item) validateFieldRules(m map[string]any, keys map[*Item]string) error {
+	for _, field := range i.Fields {
+		key, found := keys[field]
+		if !found || m[key] == nil {
+			continue
+		}
+
+		for _, rule := range field.FieldRules {
+			other := i.exactField(rule.Field)
+			if other == nil {
+				return ErrInvalidFieldName.Context(field.Name).Value(rule.Field)
+			}
+
+			otherKey, found := keys[other]
+			if !found || m[otherKey] == nil {
+				continue
+			}
+
+			if !field.compareField(rule.Op, m[key], other, m[otherKey]) {
+				return fieldRuleError(field, rule, m[key])
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldRuleError returns the error reported when a value does not satisfy a
+// cross-field comparison rule.
+func fieldRuleError(field *Item, rule FieldRule, v any) error {
+	return ErrFieldComparison.Context(field.Name).Value(v).Expected(rule.Op + "=" + rule.Field)
+}
+
+// compareField reports if the value of this field satisfies the comparison
+// with the value of the other field. The values are compared as the type of
+// this field. If the other value cannot be converted to that type, or the type
+// is not ordered and the comparison needs an order, the rule is not satisfied.
+func (i *Item) compareField(op string, v any, other *Item, otherValue any) bool {
+	if i.Quoted {
+		v, _ = i.quotedValue(v)
+	}
+
+	if other.Quoted {
+		otherValue, _ = other.quotedValue(otherValue)
+	}
+
+	base := i
+	for base.ItemType == TypePointer && base.BaseType != nil {
+		base = base.BaseType
+	}
+
+	result, ordered, ok := base.compareValues(v, otherValue)
+	if !ok {
+		return false
+	}
+
+	switch op {
+	case RuleEqualField:
+		return result == 0
+
+	case RuleNotEqualField:
+		return result != 0
+	}
+
+	if !ordered {
+		return false
+	}
+
+	switch op {
+	case RuleGreaterField:
+		return result > 0
+
+	case RuleGreaterEqualField:
+		return result >= 0
+
+	case RuleLessField:
+		return result < 0
+
+	case RuleLessEqualField:
+		return result <= 0
+	}
+
+	return false
+}
+
+// compareValues compares two values as the type of the item. It returns a
+// negative number, zero, or a positive number when a is less than, equal to,
+// or greater than b. The ordered flag is false if the type has no order, in
+// which case the result only reports if the values are equal. The ok flag is
+// false if either value cannot be converted to the type.
+func (i *Item) compareValues(a, b any) (result int, ordered bool, ok bool) {
+	switch i.ItemType {
+	case TypeInt:
+		x, errA := getIntegerValue(a)
+		y, errB := getIntegerValue(b)
+
+		if errA != nil || errB != nil {
+			return 0, true, false
+		}
+
+		return x.Cmp(y), true, true
+
+	case TypeFloat:
+		if i.Decimal {
+			x, errA := getDecimalValue(a)
+			y, errB := getDecimalValue(b)
+
+			if errA != nil || errB != nil {
+				return 0, true, false
+			}
+
+			return x.Cmp(y), true, true
+		}
+
+		x, errA := getFloatValue(a)
+		y, errB := getFloatValue(b)
+
+		return cmp.Compare(x, y), true, errA == nil && errB == nil
+
+	case TypeTime:
+		x, errA := getTimeValue(a)
+		y, errB := getTimeValue(b)
+
+		return x.Compare(y), true, errA == nil && errB == nil
+
+	case TypeDuration:
+		x, errA := getDurationValue(a)
+		y, errB := getDurationValue(b)
+
+		return cmp.Compare(x, y), true, errA == nil && errB == nil
+
+	case TypeString, TypeList:
+		x, errA := getStringValue(a)
+		y, errB := getStringValue(b)
+
+		return strings.Compare(x, y), true, errA == nil && errB == nil
+
+	case TypeUUID:
+		x, errA := getUUIDValue(a)
+		y, errB := getUUIDValue(b)
+
+		return strings.Compare(x.String(), y.String()), false, errA == nil && errB == nil
+
+	case TypeCustom:
+		if custom, found := findCustomType(i.TypeName); found && custom.handler != nil {
+			x, errA := custom.handler.Value(a)
+			y, errB := custom.handler.Value(b)
+			if errA != nil || errB != nil {
+				return 0, false, false
+			}
+
+			return custom.handler.Compare(x, y), true, true
+		}
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0, false, true
+	}
+
+	return 1, false, true
+}
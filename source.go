@@ -0,0 +1,392 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The indentation used for each nesting level of a structure when a
+// validator is formatted as source text.
+const sourceIndent = "    "
+
+// Patterns used to decide if a name or value must be quoted when it is
+// written as validator source. Anything that does not match these patterns
+// is written as a quoted string, which Compile() will unquote.
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	plainValuePattern = regexp.MustCompile(`^[A-Za-z0-9_.+\-]+$`)
+)
+
+// Source returns the validator expressed in the validator definition language
+// accepted by Compile(). The text is written in a canonical form, with one field
+// per line and nested structures indented. Compiling the resulting text creates
+// a validator equivalent to the original.
+//
+// Numeric and time limits are written as text, so a validator created with
+// New() and recompiled from its source has string limit values rather than the
+// native Go values, which are validated the same way.
+func (i *Item) Source() string {
+	if i == nil {
+		return ""
+	}
+
+	var b strings.Builder
+
+	i.writeSource(&b, 0)
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// Format reads validator source text and returns it in the canonical form
+// generated by the Source() method. If the source text cannot be compiled,
+// the compilation error is returned.
+func Format(src string) (string, error) {
+	item, err := Compile(src)
+	if err != nil {
+		return "", err
+	}
+
+	return item.Source(), nil
+}
+
+// writeSource writes the named declaration for an item to the builder. The
+// depth is the nesting level of the item, used to indent structure fields.
+func (i *Item) writeSource(b *strings.Builder, depth int) {
+	if i.Name != "" {
+		b.WriteString(quoteName(i.Name))
+		b.WriteString(" ")
+	}
+
+	i.writeDefinition(b, depth)
+}
+
+// writeDefinition writes the type declaration for an item, followed by the
+// attributes of the item, if any.
+func (i *Item) writeDefinition(b *strings.Builder, depth int) {
+	i.writeType(b, depth)
+
+	// The attributes of a map are written as part of the key declaration, and
+	// the attributes that follow the declaration belong to the map value.
+	if i.ItemType == TypeMap && i.BaseType != nil && i.Alias == "" {
+		return
+	}
+
+	if attributes := i.attributes(); len(attributes) > 0 {
+		b.WriteString(": ")
+		b.WriteString(strings.Join(attributes, ", "))
+	}
+}
+
+// definition returns the type declaration and attributes of an item, without
+// its name.
+func (i *Item) definition() string {
+	var b strings.Builder
+
+	i.writeDefinition(&b, 0)
+
+	return b.String()
+}
+
+// writeType writes the type declaration for an item. For structures, this
+// includes the declarations of each of the fields.
+func (i *Item) writeType(b *strings.Builder, depth int) {
+	if i.Alias != "" {
+		b.WriteString("@")
+		b.WriteString(quoteName(i.Alias))
+
+		return
+	}
+
+	switch i.ItemType {
+	case TypePointer, TypeArray:
+		if i.ItemType == TypePointer {
+			b.WriteString("*")
+		} else {
+			b.WriteString("[]")
+		}
+
+		switch {
+		case i.BaseType == nil:
+			b.WriteString(typeWord(TypeAny))
+
+		case i.BaseType.isGroupedMap():
+			// The rules for the values of the map would be followed by the
+			// rules for the array or pointer, so the map is grouped in
+			// parentheses to keep them apart.
+			b.WriteString("(")
+			i.BaseType.writeDefinition(b, depth)
+			b.WriteString(")")
+
+		default:
+			i.BaseType.writeType(b, depth)
+		}
+
+	case TypeTuple:
+		i.writeTuple(b, depth)
+
+	case TypeStruct:
+		b.WriteString("{\n")
+
+		for _, field := range i.Fields {
+			b.WriteString(strings.Repeat(sourceIndent, depth+1))
+			field.writeSource(b, depth+1)
+			b.WriteString("\n")
+		}
+
+		b.WriteString(strings.Repeat(sourceIndent, depth))
+		b.WriteString("}")
+
+	case TypeMap:
+		b.WriteString(typeWord(TypeMap))
+
+		if i.BaseType == nil {
+			return
+		}
+
+		b.WriteString("[")
+
+		if i.KeyType != nil && i.KeyType.ItemType != TypeString {
+			i.KeyType.writeType(b, depth)
+		} else {
+			b.WriteString(typeWord(TypeString))
+		}
+
+		if attributes := i.attributes(); len(attributes) > 0 {
+			b.WriteString(": ")
+			b.WriteString(strings.Join(attributes, ", "))
+		}
+
+		b.WriteString("] ")
+		i.BaseType.writeDefinition(b, depth)
+
+	case TypeUnion:
+		i.writeUnion(b, depth)
+
+	case TypeCustom:
+		b.WriteString(typeWord(TypeCustom))
+		b.WriteString(" ")
+		b.WriteString(strconv.Quote(i.TypeName))
+
+	default:
+		b.WriteString(typeWord(i.ItemType))
+	}
+}
+
+// isGroupedMap reports if an item is a map with a value type, which is written
+// in parentheses when it is the value of an array or pointer.
+func (i *Item) isGroupedMap() bool {
+	return i.ItemType == TypeMap && i.BaseType != nil && i.Alias == ""
+}
+
+// attributes returns the list of tag strings that describe the rules for
+// this item. These are formatted as they would be written in a validate tag,
+// and can be parsed by ParseTag().
+func (i *Item) attributes() []string {
+	list := []string{}
+
+	if i.Required {
+		list = append(list, "required")
+	}
+
+	// Pointers are always nullable when they are compiled.
+	if i.Nullable && i.ItemType != TypePointer {
+		list = append(list, "nullable")
+	}
+
+	if i.AllowForeignKey {
+		list = append(list, "foreignkeys")
+	}
+
+	if i.CaseSensitive {
+		list = append(list, "matchcase")
+	}
+
+	if i.Quoted {
+		list = append(list, "quoted")
+	}
+
+	if i.FieldMatch != "" {
+		list = append(list, "fieldmatch="+quoteValue(i.FieldMatch))
+	}
+
+	if i.NameMatch != "" {
+		list = append(list, "namematch="+quoteValue(i.NameMatch))
+	}
+
+	for _, group := range i.Groups {
+		fields := make([]string, len(group.Fields))
+		for n, field := range group.Fields {
+			fields[n] = quoteValue(field)
+		}
+
+		list = append(list, group.Op+"=("+strings.Join(fields, ",")+")")
+	}
+
+	for _, condition := range i.Conditions {
+		text := condition.Op + "=" + quoteValue(condition.Field)
+		if condition.Op == RuleRequiredIf || condition.Op == RuleExcludedIf {
+			text += ":" + quoteValue(condition.Value)
+		}
+
+		list = append(list, text)
+	}
+
+	for _, rule := range i.FieldRules {
+		list = append(list, rule.Op+"="+quoteValue(rule.Field))
+	}
+
+	// Rule expressions are wrapped in single quotes, so they can contain
+	// commas and double-quoted strings.
+	for _, text := range i.Expressions {
+		if strings.Contains(text, "'") {
+			list = append(list, "rule="+quoteValue(text))
+		} else {
+			list = append(list, "rule='"+text+"'")
+		}
+	}
+
+	// The values of the keys that are not fields are written as a type
+	// declaration inside parentheses.
+	for _, property := range i.PatternProperties {
+		text := "property=(" + strconv.Quote(property.Pattern)
+		if property.Value != nil {
+			text += " " + property.Value.definition()
+		}
+
+		list = append(list, text+")")
+	}
+
+	if i.AdditionalProperties != nil {
+		list = append(list, "additional=("+i.AdditionalProperties.definition()+")")
+	}
+
+	if i.AdditionalItems != nil {
+		list = append(list, "additional=("+i.AdditionalItems.definition()+")")
+	}
+
+	list = append(list, i.elementAttributes()...)
+
+	if i.Discriminator != "" {
+		list = append(list, "discriminator="+quoteValue(i.Discriminator))
+	}
+
+	if i.UnionMode != "" {
+		list = append(list, "mode="+i.UnionMode)
+	}
+
+	if i.HasMinLength {
+		list = append(list, "minlen="+strconv.Itoa(i.MinLength))
+	}
+
+	if i.HasMaxLength {
+		list = append(list, "maxlen="+strconv.Itoa(i.MaxLength))
+	}
+
+	// An exclusive limit is written using the "gt" or "lt" keyword.
+	if i.HasMinValue && i.ExclusiveMinValue {
+		list = append(list, "gt="+quoteValue(formatValue(i.MinValue)))
+	} else if i.HasMinValue {
+		list = append(list, "minvalue="+quoteValue(formatValue(i.MinValue)))
+	}
+
+	if i.HasMaxValue && i.ExclusiveMaxValue {
+		list = append(list, "lt="+quoteValue(formatValue(i.MaxValue)))
+	} else if i.HasMaxValue {
+		list = append(list, "maxvalue="+quoteValue(formatValue(i.MaxValue)))
+	}
+
+	if i.MultipleOf != nil {
+		list = append(list, "multipleof="+quoteValue(formatValue(i.MultipleOf)))
+	}
+
+	if i.Decimal {
+		list = append(list, "decimal")
+	}
+
+	if i.Pattern != "" {
+		list = append(list, "pattern="+quoteValue(i.Pattern))
+	}
+
+	if len(i.Enums) > 0 {
+		enums := make([]string, len(i.Enums))
+		for n, enum := range i.Enums {
+			enums[n] = quoteValue(enum)
+		}
+
+		list = append(list, "enum=("+strings.Join(enums, ",")+")")
+	}
+
+	// The rules for the keys of a map are written using a key clause.
+	if i.KeyType != nil {
+		if attributes := i.KeyType.attributes(); len(attributes) > 0 {
+			list = append(list, "key=("+strings.Join(attributes, ", ")+")")
+		}
+	}
+
+	// The rules for the values of an array or pointer are written using a
+	// base clause. Like ParseTag(), this skips over a nested array or pointer
+	// to reach the underlying value type. Map values write their own rules.
+	if base := i.BaseType; base != nil && (i.ItemType == TypeArray || i.ItemType == TypePointer) {
+		if (base.ItemType == TypeArray || base.ItemType == TypePointer) && base.BaseType != nil {
+			base = base.BaseType
+		}
+
+		if base.ItemType != TypeMap || base.BaseType == nil {
+			if attributes := base.attributes(); len(attributes) > 0 {
+				list = append(list, "base=("+strings.Join(attributes, ", ")+")")
+			}
+		}
+	}
+
+	return list
+}
+
+// typeWord returns the reserved word used for a type in validator source.
+func typeWord(kind Type) string {
+	for word, t := range TypeNamesMap {
+		if t == kind {
+			return word
+		}
+	}
+
+	return kind.String()
+}
+
+// formatValue converts a limit value to the text used to represent it
+// in validator source.
+func formatValue(v any) string {
+	switch actual := v.(type) {
+	case time.Time:
+		return actual.Format(time.RFC3339Nano)
+
+	case time.Duration:
+		return actual.String()
+
+	default:
+		return fmt.Sprintf("%v", actual)
+	}
+}
+
+// quoteName returns a name as it must be written in validator source. Names
+// that are not identifiers, or that could be mistaken for a type, are quoted.
+func quoteName(name string) string {
+	if identifierPattern.MatchString(name) && !isTypeStart(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+// quoteValue returns a value as it must be written in validator source.
+// Values that contain punctuation or spaces are quoted.
+func quoteValue(value string) string {
+	if plainValuePattern.MatchString(value) {
+		return value
+	}
+
+	return strconv.Quote(value)
+}
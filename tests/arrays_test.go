@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Member is an element of an array that must have unique ids.
+type Member struct {
+	ID   int    `json:"id"   validate:"required,minvalue=1"`
+	Name string `json:"name"`
+}
+
+// Team has arrays with rules for their values as a whole.
+type Team struct {
+	Members []Member `json:"members" validate:"unique_by=id"`
+	Tags    []string `json:"tags"    validate:"unique,maxlen=4"`
+	Roles   []string `json:"roles"   validate:"required,contains=(string: enum=owner),maxcontains=1"`
+}
+
+func Test_ArrayRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid team",
+			`{"members": [{"id": 1, "name": "a"}, {"id": 2, "name": "a"}], "tags": ["x", "y"], "roles": ["owner", "admin"]}`,
+			"",
+		},
+		{
+			"Invalid members, duplicate id",
+			`{"members": [{"id": 1}, {"id": 2}, {"id": 1, "name": "b"}], "roles": ["owner"]}`,
+			`duplicate array value, in members: "2"`,
+		},
+		{
+			"Invalid tags, duplicate value",
+			`{"tags": ["x", "y", "x"], "roles": ["owner"]}`,
+			`duplicate array value, in tags: "2"`,
+		},
+		{
+			"Invalid roles, no owner",
+			`{"roles": ["admin", "user"]}`,
+			`number of matching array values out of range, in roles: "0", expected 1`,
+		},
+		{
+			"Invalid roles, more than one owner",
+			`{"roles": ["owner", "admin", "owner"]}`,
+			`number of matching array values out of range, in roles: "2", expected 1`,
+		},
+	}
+
+	item, err := validator.New(&Team{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_ArrayRuleDefinitions(t *testing.T) {
+	item, err := validator.Compile(`{ points [](int, int): unique; scores []int: contains=(int: minvalue=90), mincontains=2, maxcontains=3 }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    points [](int, int): unique\n    scores []int: contains=(int: minvalue=90), mincontains=2, maxcontains=3\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"points": [[1, 2], [2, 1]], "scores": [95, 90, 10]}`, ""},
+		{`{"points": [[1, 2], [2, 1], [1, 2]], "scores": [95, 90]}`, `duplicate array value, in points: "2"`},
+		{`{"scores": [95, 80]}`, `number of matching array values out of range, in scores: "1", expected 2`},
+		{`{"scores": [95, 91, 92, 93]}`, `number of matching array values out of range, in scores: "4", expected 3`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := copied.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	if text := copied.Describe(); text != "object\n    points: array of tuple of integer, integer, unique values\n"+
+		"    scores: array of integer, containing between 2 and 3 integer, at least 90\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Setting the rules directly. Objects are compared by their contents.
+	api := validator.NewType(validator.TypeArray).SetUnique(true)
+	api.BaseType = validator.NewType(validator.TypeAny)
+
+	if err := api.Validate(`[{"a": 1, "b": 2}, {"a": 1}]`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := api.Validate(`[{"a": 1, "b": 2}, {"b": 2, "a": 1}]`); err == nil || err.Error() != `duplicate array value: "1"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeString).ParseTag("unique"); err == nil ||
+		err.Error() != `keyword only valid with array type, in unique: "string"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "array", "base_type": {"type": "int"}, "min_contains": 1, "has_min_contains": true}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for mincontains without contains")
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "array", "base_type": {"type": "struct", "fields": [{"name": "id", "type": "int"}]}, "unique_by": "key"}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for an unknown unique_by field")
+	}
+}
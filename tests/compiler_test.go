@@ -73,6 +73,49 @@ func TestCompile(t *testing.T) {
 			src:  "int: omit=true",
 			err:  validator.ErrInvalidKeyword.Value("omit"),
 		},
+		{
+			name: "missing semicolon after an attribute value",
+			src:  "{ a int: minvalue=1 b int }",
+			err:  validator.ErrSyntaxError.Context("line 1, column 21").Value("b").Expected(",", ";"),
+		},
+		{
+			name: "missing comma after a list of values",
+			src:  "string: enum=(a, b) required",
+			err:  validator.ErrSyntaxError.Context("line 1, column 21").Value("required").Expected(",", ";"),
+		},
+		{
+			name: "integer type with invalid minimum",
+			src:  "int: minvalue=ten",
+			err:  validator.ErrInvalidData.Context("minvalue").Value("ten"),
+		},
+		{
+			name: "integer type with spaced attributes",
+			src:  "int: minvalue = -5 , max=5",
+			want: &validator.Item{
+				ItemType:    validator.TypeInt,
+				HasMinValue: true,
+				MinValue:    "-5",
+				HasMaxValue: true,
+				MaxValue:    "5",
+			},
+		},
+		{
+			name: "type words used as field names",
+			src:  "{ any string; union int; custom *int }",
+			want: &validator.Item{
+				ItemType: validator.TypeStruct,
+				Fields: []*validator.Item{
+					{Name: "any", ItemType: validator.TypeString},
+					{Name: "union", ItemType: validator.TypeInt},
+					{Name: "custom", ItemType: validator.TypePointer, Nullable: true, BaseType: &validator.Item{ItemType: validator.TypeInt}},
+				},
+			},
+		},
+		{
+			name: "custom type without a type name",
+			src:  "{ value custom int }",
+			err:  validator.ErrSyntaxError.Context("line 1, column 16").Value("int").Expected("type name"),
+		},
 		{
 			name: "simple map type",
 			src:  "map[string] int",
@@ -287,3 +330,47 @@ age int;
 		})
 	}
 }
+
+func TestDeepCopy(t *testing.T) {
+	item, err := validator.Compile(`{ name string: required, enum=(Tom, Mary); scores []int: minlen=1, base=(minvalue=0) }: foreignkeys`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	copied := validator.DeepCopy(item)
+	if copied.String() != item.String() {
+		t.Errorf("DeepCopy() = %v, want %v", copied, item)
+	}
+
+	copied.Fields[0].Enums[0] = "Sue"
+	if item.Fields[0].Enums[0] != "Tom" {
+		t.Errorf("DeepCopy() shares values with the original")
+	}
+
+	if validator.DeepCopy(nil) != nil {
+		t.Errorf("DeepCopy(nil) expected nil")
+	}
+}
+
+func TestEnumType(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{`bool: enum=(true)`, `invalid field type for enum, must be string or int, in enum: "bool"`},
+		{`{ name string }: enum=(a, b)`, `invalid field type for enum, must be string or int, in enum: "struct"`},
+		{`*{ name string }: enum=(a, b)`, `invalid field type for enum, must be string or int, in enum: "struct"`},
+		{`*string: enum=(a, b)`, ``},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if _, err := validator.Compile(test.src); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Errorf("Compile(%q) unexpected result: %s", test.src, msg)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Payment has fields that are required, or not allowed, depending on the
+// values of other fields.
+type Payment struct {
+	Type       string `json:"type"        validate:"required,enum=card|cash|transfer"`
+	CardNumber string `json:"card_number" validate:"required_if=type:card,excluded_if=type:cash,minlen=12"`
+	Expiry     string `json:"expiry"      validate:"required_with=card_number"`
+	Account    string `json:"account"     validate:"required_without=card_number"`
+	Amount     int    `json:"amount"      validate:"minvalue=1"`
+	Receipt    bool   `json:"receipt"     validate:"required_if=amount:1000"`
+}
+
+func Test_Conditions(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid card payment",
+			`{"type": "card", "card_number": "123456789012", "expiry": "12/30"}`,
+			"",
+		},
+		{
+			"Valid cash payment",
+			`{"type": "cash", "account": "petty"}`,
+			"",
+		},
+		{
+			"Invalid card payment, missing card number",
+			`{"type": "card", "account": "petty"}`,
+			`required field missing: "card_number", expected required_if=type:card`,
+		},
+		{
+			"Invalid cash payment, card number not allowed",
+			`{"type": "cash", "card_number": "123456789012", "expiry": "12/30"}`,
+			`field not allowed: "card_number", expected excluded_if=type:cash`,
+		},
+		{
+			"Invalid card payment, missing expiry",
+			`{"type": "card", "card_number": "123456789012"}`,
+			`required field missing: "expiry", expected required_with=card_number`,
+		},
+		{
+			"Invalid transfer, missing account",
+			`{"type": "transfer"}`,
+			`required field missing: "account", expected required_without=card_number`,
+		},
+		{
+			"Invalid transfer, null card number",
+			`{"type": "transfer", "card_number": null}`,
+			`invalid data, in card_number`,
+		},
+		{
+			"Valid amount",
+			`{"type": "transfer", "account": "x", "amount": 999}`,
+			"",
+		},
+		{
+			"Invalid amount, missing receipt",
+			`{"type": "transfer", "account": "x", "amount": 1000}`,
+			`required field missing: "receipt", expected required_if=amount:1000`,
+		},
+		{
+			"Invalid type, reported before conditions of later fields",
+			`{"type": "cheque", "card_number": "123456789012"}`,
+			`invalid enumerated value, in type: "cheque", expected one of card, cash, transfer`,
+		},
+	}
+
+	item, err := validator.New(&Payment{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_ConditionDefinitions(t *testing.T) {
+	_, err := validator.Compile(`{ kind string; value int: required_if=kind }`)
+	if err == nil || err.Error() != `invalid condition, in required_if: "kind"` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	_, err = validator.Compile(`{ kind string; value int: required_with=other }`)
+	if err == nil || err.Error() != `invalid field name, in value: "other"` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	item, err := validator.Compile(`{ kind string; value int: required_if=kind:"big one", excluded_if=kind:small }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    kind string\n    value int: required_if=kind:\"big one\", excluded_if=kind:small\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"kind": "big one"}`); err == nil || err.Error() != `required field missing: "value", expected required_if=kind:big one` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object\n    kind: string\n    value: integer, required when kind is \"big one\", not allowed when kind is \"small\"\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+}
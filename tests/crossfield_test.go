@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tucats/validator"
+)
+
+// Booking has fields that must be compared with other fields.
+type Booking struct {
+	Start       time.Time     `json:"start"        validate:"required"`
+	End         time.Time     `json:"end"          validate:"required,gtfield=start"`
+	MinReplicas int           `json:"min_replicas" validate:"minvalue=1"`
+	MaxReplicas int           `json:"max_replicas" validate:"gtefield=min_replicas"`
+	Budget      float64       `json:"budget"`
+	Spent       float64       `json:"spent"        validate:"ltefield=budget"`
+	Timeout     time.Duration `json:"timeout"`
+	Grace       time.Duration `json:"grace"        validate:"ltfield=timeout"`
+	Password    string        `json:"password"     validate:"minlen=8"`
+	Confirm     string        `json:"confirm"      validate:"eqfield=password"`
+	Previous    *string       `json:"previous"     validate:"nefield=password"`
+}
+
+func Test_CrossField(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid times",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-01T12:00:00Z"}`,
+			"",
+		},
+		{
+			"Invalid times, end before start",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-01T09:00:00Z"}`,
+			`field comparison failed, in end: "2024-01-01T09:00:00Z", expected gtfield=start`,
+		},
+		{
+			"Invalid times, end equal to start",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-01T10:00:00Z"}`,
+			`field comparison failed, in end: "2024-01-01T10:00:00Z", expected gtfield=start`,
+		},
+		{
+			"Valid integers, equal",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "min_replicas": 3, "max_replicas": 3}`,
+			"",
+		},
+		{
+			"Invalid integers",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "min_replicas": 3, "max_replicas": 2}`,
+			`field comparison failed, in max_replicas: "2", expected gtefield=min_replicas`,
+		},
+		{
+			"Valid integers, other field missing",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "max_replicas": 0}`,
+			"",
+		},
+		{
+			"Invalid integer, checked before comparison",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "min_replicas": 0, "max_replicas": 5}`,
+			`value out of range, in min_replicas: "0"`,
+		},
+		{
+			"Invalid floats",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "budget": 10.5, "spent": 10.75}`,
+			`field comparison failed, in spent: "10.75", expected ltefield=budget`,
+		},
+		{
+			"Valid durations",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "timeout": "1m", "grace": "59s"}`,
+			"",
+		},
+		{
+			"Invalid durations",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "timeout": "1m", "grace": "60s"}`,
+			`field comparison failed, in grace: "60s", expected ltfield=timeout`,
+		},
+		{
+			"Valid strings",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "password": "sw0rdfish", "confirm": "sw0rdfish", "previous": "hunter22"}`,
+			"",
+		},
+		{
+			"Invalid strings, not equal",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "password": "sw0rdfish", "confirm": "swordfish"}`,
+			`field comparison failed, in confirm: "swordfish", expected eqfield=password`,
+		},
+		{
+			"Invalid strings, equal",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "password": "sw0rdfish", "previous": "sw0rdfish"}`,
+			`field comparison failed, in previous: "sw0rdfish", expected nefield=password`,
+		},
+		{
+			"Valid strings, other field missing",
+			`{"start": "2024-01-01T10:00:00Z", "end": "2024-01-02T10:00:00Z", "previous": "hunter22"}`,
+			"",
+		},
+	}
+
+	item, err := validator.New(&Booking{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_CrossFieldDefinitions(t *testing.T) {
+	type Unknown struct {
+		Low  int `json:"low"`
+		High int `json:"high" validate:"gtfield=Low"`
+	}
+
+	_, err := validator.New(&Unknown{})
+	if err == nil || err.Error() != `invalid field name, in high: "Low"` {
+		t.Fatalf("New() unexpected result: %v", err)
+	}
+
+	_, err = validator.Compile(`{ low int; high int: gtfield=middle }`)
+	if err == nil || err.Error() != `invalid field name, in high: "middle"` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	_, err = validator.NewJSON([]byte(`{"type": "struct", "fields": [
+		{"name": "low", "type": "int"},
+		{"name": "high", "type": "int", "field_rules": [{"op": "gtfield", "field": "middle"}]}]}`))
+	if err == nil || err.Error() != `invalid field name, in high: "middle"` {
+		t.Fatalf("NewJSON() unexpected result: %v", err)
+	}
+
+	// The rules are kept when the validator is written as source or JSON.
+	item, err := validator.Compile(`{ low int; high int: gtfield=low, nefield=low }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    low int\n    high int: gtfield=low, nefield=low\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"low": 5, "high": 5}`); err == nil || err.Error() != `field comparison failed, in high: "5", expected gtfield=low` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object\n    low: integer\n    high: integer, greater than low, not equal to low\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+}
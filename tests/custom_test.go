@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Color is an enumerated type that decodes its own JSON text.
+type Color int
+
+var errInvalidColor = errors.New("unknown color")
+
+func (c *Color) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "red":
+		*c = 1
+	case "green":
+		*c = 2
+	default:
+		return errInvalidColor
+	}
+
+	return nil
+}
+
+func (c Color) MarshalText() ([]byte, error) {
+	if c == 2 {
+		return []byte("green"), nil
+	}
+
+	return []byte("red"), nil
+}
+
+type CustomObject struct {
+	Address net.IP     `json:"address"`
+	Server  netip.Addr `json:"server"`
+	Total   *big.Int   `json:"total"`
+	Color   Color      `json:"color"`
+	Colors  []Color    `json:"colors"`
+}
+
+func Test_Custom(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid custom types",
+			`{"address": "10.0.0.1", "server": "::1", "total": 1234567890, "color": "Red", "colors": ["green"]}`,
+			"",
+		},
+		{
+			"Invalid IP address",
+			`{"address": "10.0.0.256"}`,
+			`invalid data, in address: "10.0.0.256" (invalid IP address: 10.0.0.256)`,
+		},
+		{
+			"Invalid IP address, not a string",
+			`{"server": 10}`,
+			`invalid data, in server: "10"`,
+		},
+		{
+			"Invalid big integer",
+			`{"total": "many"}`,
+			`invalid data: "many" (math/big: cannot unmarshal "\"many\"" into a *big.Int)`,
+		},
+		{
+			"Invalid enumerated type",
+			`{"colors": ["red", "blue"]}`,
+			`invalid data: "blue" (unknown color)`,
+		},
+	}
+
+	item, err := validator.New(&CustomObject{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+
+		if err != nil && test.name == "Invalid enumerated type" && !errors.Is(err, errInvalidColor) {
+			t.Errorf("In \"%s\", error does not wrap the cause: %v", test.name, err)
+		}
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_CustomCompile(t *testing.T) {
+	if err := validator.RegisterUnmarshaler(Color(0)); err != nil {
+		t.Fatalf("RegisterUnmarshaler() unexpected error: %v", err)
+	}
+
+	if err := validator.RegisterUnmarshaler(0); err == nil {
+		t.Errorf("RegisterUnmarshaler() expected error for int")
+	}
+
+	item, err := validator.Compile(`[]custom "github.com/tucats/validator/tests.Color": minlen=1`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if err := item.Validate(`["green", "red"]`); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	if err := item.Validate(`["blue"]`); err == nil {
+		t.Errorf("Validate() expected error for invalid color")
+	}
+
+	want := "[]custom \"github.com/tucats/validator/tests.Color\": minlen=1\n"
+	if got := item.Source(); got != want {
+		t.Errorf("Source() = %q, want %q", got, want)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`["blue"]`); err == nil {
+		t.Errorf("Validate() expected error for invalid color")
+	}
+
+	// A type is named by its import path, so types with the same name in
+	// different packages are kept apart.
+	if err := validator.RegisterUnmarshaler(netip.Addr{}); err != nil {
+		t.Fatalf("RegisterUnmarshaler() unexpected error: %v", err)
+	}
+
+	short, err := validator.Compile(`custom "netip.Addr"`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if err := short.Validate(`"::1"`); err == nil || err.Error() != `unsupported type: "netip.Addr"` {
+		t.Errorf("Validate() unexpected result: %v", err)
+	}
+
+	addr, err := validator.Compile(`custom "net/netip.Addr"`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if err := addr.Validate(`"::1"`); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	if err := addr.Validate(`"10.0.0.256"`); err == nil {
+		t.Errorf("Validate() expected error for invalid address")
+	}
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+func TestItem_Describe(t *testing.T) {
+	item, err := validator.New(&Employees{})
+	if err != nil {
+		t.Fatalf("Failed to define structure: %v", err)
+	}
+
+	want := `Employees
+    department: required string
+    division: required string, one of "HR", "Finance", "Marketing", "Engineering"
+    staff: array of at least 1 Person
+        name: required string, with length between 1 and 100
+        age: required integer, between 18 and 65
+        address: required Address
+            street: required string, with length between 1 and 100
+            city: required string, with length between 1 and 100
+`
+
+	if got := item.Describe(); got != want {
+		t.Errorf("Describe() = %s, want %s", got, want)
+	}
+
+	item, err = validator.Compile("map[string: enum=(a,b)] []int: minlen=2, base=(minvalue=1, maxvalue=9)")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	want = `map of array of at least 2 integer, keys one of "a", "b", values each between 1 and 9
+`
+
+	if got := item.Describe(); got != want {
+		t.Errorf("Describe() = %s, want %s", got, want)
+	}
+}
+
+func TestItem_Markdown(t *testing.T) {
+	// Create a structure that allows recursion
+	type DejaVu struct {
+		Name     string   `json:"name"     validate:"required,minlength=5,maxlength=100"`
+		Children []DejaVu `json:"children" validate:"minlength=0"`
+		Address  *Address `json:"address"`
+	}
+
+	item, err := validator.New(&DejaVu{})
+	if err != nil {
+		t.Fatalf("Failed to define structure: %v", err)
+	}
+
+	want := `| Field | Type | Required | Rules |
+| ----- | ---- | -------- | ----- |
+| name | string | yes | with length between 5 and 100 |
+| children | array of DejaVu | no |  |
+| address | Address | no |  |
+| address.street | string | yes | with length between 1 and 100 |
+| address.city | string | yes | with length between 1 and 100 |
+`
+
+	if got := item.Markdown(); got != want {
+		t.Errorf("Markdown() = %s, want %s", got, want)
+	}
+}
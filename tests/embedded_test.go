@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+type EmbeddedUser struct {
+	Name  string `json:"name"  validate:"required,minlen=2"`
+	Email string `json:"email"`
+	Note  string
+}
+
+type EmbeddedAudit struct {
+	Created string `json:"created"`
+	Note    string
+}
+
+type embeddedBase struct {
+	ID int `json:"id" validate:"minvalue=1"`
+}
+
+// Structure with promoted fields. The email field hides the field of the same
+// name in EmbeddedUser, and the rules for EmbeddedAudit apply to its fields.
+type EmbeddedAdmin struct {
+	EmbeddedUser
+	*EmbeddedAudit `validate:"required"`
+	embeddedBase
+	Email string `json:"email" validate:"enum=admin@example.com"`
+	Level int    `json:"level"`
+}
+
+// Structure where the user is not promoted because it has a json name.
+type EmbeddedConflict struct {
+	EmbeddedAudit
+	EmbeddedUser `json:"user"`
+	Extra        struct {
+		EmbeddedUser
+	} `json:"extra"`
+}
+
+// Structure where the Note field is found twice at the same depth, so neither
+// is used.
+type EmbeddedAmbiguous struct {
+	EmbeddedUser
+	EmbeddedAudit
+}
+
+func Test_Embedded(t *testing.T) {
+	type TestITem struct {
+		name     string
+		object   any
+		jsonText string
+		expected error
+	}
+
+	tests := []TestITem{
+		{
+			"Valid promoted fields",
+			&EmbeddedAdmin{},
+			`{"name": "Tom", "created": "today", "id": 3, "email": "admin@example.com", "level": 1}`,
+			nil,
+		},
+		{
+			"Invalid promoted field rule",
+			&EmbeddedAdmin{},
+			`{"name": "T", "created": "today"}`,
+			validator.ErrValueLengthOutOfRange.Context("name").Value("T"),
+		},
+		{
+			"Invalid promoted field from unexported structure",
+			&EmbeddedAdmin{},
+			`{"name": "Tom", "created": "today", "id": 0}`,
+			validator.ErrValueOutOfRange.Context("id").Value(0),
+		},
+		{
+			"Invalid field missing, required by embedded field tag",
+			&EmbeddedAdmin{},
+			`{"name": "Tom"}`,
+			validator.ErrRequired.Value("created"),
+		},
+		{
+			"Invalid shallower field hides promoted field",
+			&EmbeddedAdmin{},
+			`{"name": "Tom", "created": "today", "email": "tom@example.com"}`,
+			validator.ErrInvalidEnumeratedValue.Context("email").Value("tom@example.com").Expected("admin@example.com"),
+		},
+		{
+			"Invalid embedded structure name",
+			&EmbeddedAdmin{},
+			`{"name": "Tom", "created": "today", "EmbeddedUser": {}}`,
+			validator.ErrInvalidFieldName.Value("EmbeddedUser"),
+		},
+		{
+			"Valid named embedded structure",
+			&EmbeddedConflict{},
+			`{"created": "today", "Note": "audit", "user": {"name": "Tom"}, "extra": {"name": "Tom"}}`,
+			nil,
+		},
+		{
+			"Invalid named embedded structure",
+			&EmbeddedConflict{},
+			`{"user": {}}`,
+			validator.ErrRequired.Value("name"),
+		},
+		{
+			"Invalid field name found twice at the same depth",
+			&EmbeddedAmbiguous{},
+			`{"name": "Tom", "Note": "text"}`,
+			validator.ErrInvalidFieldName.Value("Note"),
+		},
+		{
+			"Valid fields that are not ambiguous",
+			&EmbeddedAmbiguous{},
+			`{"name": "Tom", "email": "tom@example.com", "created": "today"}`,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		var (
+			msg1 string
+			msg2 string
+		)
+
+		item, err := validator.New(test.object)
+		if err != nil {
+			t.Fatal("Failed to define structure:", err)
+		}
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg1 = err.Error()
+		}
+
+		if test.expected != nil {
+			msg2 = test.expected.Error()
+		}
+
+		if msg1 != msg2 {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+}
+
+func Test_EmbeddedMarshal(t *testing.T) {
+	admin := EmbeddedAdmin{
+		EmbeddedUser:  EmbeddedUser{Name: "Tom", Email: "tom@example.com"},
+		EmbeddedAudit: &EmbeddedAudit{Created: "today"},
+		embeddedBase:  embeddedBase{ID: 5},
+		Email:         "admin@example.com",
+	}
+
+	b, err := json.Marshal(admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := validator.New(&EmbeddedAdmin{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	if err := item.Validate(string(b)); err != nil {
+		t.Errorf("Validate(%s) unexpected error: %v", b, err)
+	}
+}
@@ -0,0 +1,272 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tucats/validator"
+)
+
+// Bill has rules that involve more than one field, which are written as
+// expressions in the validate tag of the "_" field.
+type Bill struct {
+	_      struct{}   `validate:"rule='total == sum(.lines[*].amount)',rule='status == \"draft\" || len(lines) > 0'"`
+	Status string     `json:"status" validate:"required,enum=draft|final"`
+	Total  float64    `json:"total"`
+	Lines  []BillLine `json:"lines"`
+	Issued time.Time  `json:"issued" validate:"required"`
+}
+
+// BillLine is a single line of a bill.
+type BillLine struct {
+	Item   string  `json:"item"`
+	Amount float64 `json:"amount" validate:"minvalue=0"`
+}
+
+func Test_Expressions(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid bill",
+			`{"status": "final", "total": 15, "lines": [{"amount": 10}, {"amount": 5}], "issued": "2026-01-01T00:00:00Z"}`,
+			"",
+		},
+		{
+			"Valid draft, no lines",
+			`{"status": "draft", "total": 0, "issued": "2026-01-01T00:00:00Z"}`,
+			"",
+		},
+		{
+			"Invalid total",
+			`{"status": "final", "total": 20, "lines": [{"amount": 10}, {"amount": 5}], "issued": "2026-01-01T00:00:00Z"}`,
+			`rule failed: "total == sum(.lines[*].amount)"`,
+		},
+		{
+			"Invalid final bill, no lines",
+			`{"status": "final", "total": 0, "lines": [], "issued": "2026-01-01T00:00:00Z"}`,
+			`rule failed: "status == \"draft\" || len(lines) > 0"`,
+		},
+		{
+			"Invalid line, checked before the rules",
+			`{"status": "final", "total": 15, "lines": [{"amount": -1}], "issued": "2026-01-01T00:00:00Z"}`,
+			`value out of range, in amount: "-1"`,
+		},
+	}
+
+	item, err := validator.New(&Bill{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_ExpressionEvaluation(t *testing.T) {
+	tests := []struct {
+		rule     string
+		jsonText string
+		expected string
+	}{
+		{`a + b * 2 == 7`, `{"a": 1, "b": 3}`, ""},
+		{`(a + b) * 2 == 8`, `{"a": 1, "b": 3}`, ""},
+		{`a % 2 == 1 && !(b < 0)`, `{"a": 3, "b": 0}`, ""},
+		{`-a == abs(a) || a >= 0`, `{"a": 3}`, ""},
+		{`max(a, b, 10) == 10 && min(a, b) == 1`, `{"a": 1, "b": 3}`, ""},
+		{`name + "!" == "x!"`, `{"name": "x"}`, ""},
+		{`len(name) <= 3`, `{"name": "long"}`, `rule failed: "len(name) <= 3"`},
+		{`items[0].n == 1 && items[5] == null`, `{"items": [{"n": 1}]}`, ""},
+		{`sum(items[*].n) == 6`, `{"items": [{"n": 1}, {"n": 2}, {"n": 3}]}`, ""},
+		{`missing == null`, `{}`, ""},
+		{`time(end) - time(start) == duration("1h")`, `{"start": "2026-01-01T10:00:00Z", "end": "2026-01-01T11:00:00Z"}`, ""},
+		{`time(start) < now()`, `{"start": "2026-01-01T10:00:00Z"}`, ""},
+		{`a / b > 1`, `{"a": 1, "b": 0}`, `rule failed: "a / b > 1" (invalid operand, in /: "0")`},
+		{`a + 1`, `{"a": 1}`, `rule failed: "a + 1" (invalid operand, in rule: "2")`},
+		{`a < "x"`, `{"a": 1}`, `rule failed: "a < \"x\"" (invalid operand, in <: "1")`},
+		{`a == 9007199254740993`, `{"a": 9007199254740992}`, `rule failed: "a == 9007199254740993"`},
+		{`a + 1 == 18446744073709551616`, `{"a": 18446744073709551615}`, ""},
+		{`a + b == 0.3 && a * 10 == 1`, `{"a": 0.1, "b": 0.2}`, ""},
+		{`a % 0.5 == -0.25 && a / 4 == -0.4375`, `{"a": -1.75}`, ""},
+		{`sum(items[*]) == 0.6 && max(items[*]) == 0.3`, `{"items": [0.1, 0.2, 0.3]}`, ""},
+		{`a / 3 + b`, `{"a": 1, "b": 0.5}`, `rule failed: "a / 3 + b" (invalid operand, in rule: "0.8333333333333334")`},
+		{`a * 2`, `{"a": 0.05}`, `rule failed: "a * 2" (invalid operand, in rule: "0.1")`},
+	}
+
+	for _, test := range tests {
+		item := validator.NewType(validator.TypeStruct).SetForeignKeys(true)
+		if err := item.AddRule(test.rule); err != nil {
+			t.Fatalf("AddRule(%q) unexpected error: %v", test.rule, err)
+		}
+
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Rule %q, unexpected result: %s", test.rule, msg)
+		}
+	}
+
+	// An evaluation error is the cause of the validation error.
+	item := validator.NewType(validator.TypeStruct).SetForeignKeys(true)
+	_ = item.AddRule("a / b > 1")
+
+	err := item.Validate(`{"a": 1, "b": 0}`)
+	if cause := errors.Unwrap(err); cause == nil || cause.Error() != `invalid operand, in /: "0"` {
+		t.Fatalf("Validate() unexpected cause: %v", cause)
+	}
+}
+
+func Test_ExpressionDefinitions(t *testing.T) {
+	tests := []struct {
+		rule     string
+		expected string
+	}{
+		{`a ==`, `syntax error, in line 1, column 4: "end of expression", expected value`},
+		{`a == (b`, `syntax error, in line 1, column 7, expected )`},
+		{`a b`, `syntax error, in line 1, column 3: "b", expected operator`},
+		{`count(a) > 1`, `syntax error, in line 1, column 1: "count", expected function name`},
+		{`abs(a, b) > 1`, `syntax error, in line 1, column 9: "abs", expected 1 argument`},
+		{`a. == 1`, `syntax error, in line 1, column 4: "=", expected field name`},
+		{`min() > 1`, `syntax error, in line 1, column 5: "min", expected at least 1 argument`},
+	}
+
+	for _, test := range tests {
+		err := validator.NewType(validator.TypeStruct).AddRule(test.rule)
+		if err == nil || err.Error() != test.expected {
+			t.Fatalf("AddRule(%q) unexpected result: %v", test.rule, err)
+		}
+	}
+
+	if err := validator.NewType(validator.TypeInt).AddRule("a == 1"); err == nil || err.Error() != `invalid rule, in a == 1: "int"` {
+		t.Fatalf("AddRule() unexpected result: %v", err)
+	}
+
+	item, err := validator.Compile(`{ low int; high int }: rule='high - low >= 10', rule="low != 3"`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    low int\n    high int\n}: rule='high - low >= 10', rule='low != 3'\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"low": 5, "high": 12}`); err == nil || err.Error() != `rule failed: "high - low >= 10"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object, satisfies \"high - low >= 10\", satisfies \"low != 3\"\n    low: integer\n    high: integer\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "struct", "expressions": ["low >"]}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for an invalid expression")
+	}
+
+	// The fields named by an expression must exist, unless the structure
+	// accepts other keys.
+	unknown := []struct {
+		src      string
+		expected string
+	}{
+		{`{ low int; high int }: rule='high > lwo'`, `invalid field name, in rule: "lwo"`},
+		{`{ low int; high int }: rule='sum(items[*].n) > high'`, `invalid field name, in rule: "items"`},
+	}
+
+	for _, test := range unknown {
+		if _, err := validator.Compile(test.src); err == nil || err.Error() != test.expected {
+			t.Fatalf("Compile(%q) unexpected result: %v", test.src, err)
+		}
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "struct", "fields": [{"name": "low", "type": "int"}], "expressions": ["low < high"]}`)); err == nil ||
+		err.Error() != `invalid field name, in rule: "high"` {
+		t.Fatalf("NewJSON() unexpected result: %v", err)
+	}
+
+	if _, err := validator.Compile(`{ low int }: foreignkeys, rule='low < high'`); err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+}
+
+// Schedule has optional fields that are compared by rule expressions, which the
+// generator must include and solve.
+type Schedule struct {
+	_     struct{}       `validate:"rule='high > low && high - low <= 100',rule='count >= 2.5 && count != 7',rule='end >= start',rule='grace < limit'"`
+	Low   *int           `json:"low"   validate:"minvalue=0,maxvalue=1000"`
+	High  *int           `json:"high"  validate:"minvalue=0,maxvalue=1000"`
+	Count int            `json:"count" validate:"maxvalue=8"`
+	Start time.Time      `json:"start"`
+	End   *time.Time     `json:"end"`
+	Grace time.Duration  `json:"grace"`
+	Limit *time.Duration `json:"limit" validate:"maxvalue=1h"`
+}
+
+func Test_ExpressionGenerator(t *testing.T) {
+	item, err := validator.New(&Schedule{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		text, err := g.Valid(item)
+		if err != nil {
+			t.Fatalf("Valid() unexpected error with seed %d: %v", seed, err)
+		}
+
+		if err := item.Validate(text); err != nil {
+			t.Fatalf("Valid() document with seed %d is not valid: %v\n%s", seed, err, text)
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
@@ -0,0 +1,211 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Structure that uses the encoding/json field tag options.
+type JSONTagObject struct {
+	ID       int     `json:"id,string"     validate:"minvalue=1"`
+	Ratio    float64 `json:"ratio,string"`
+	Enabled  *bool   `json:"enabled,string"`
+	Label    string  `json:"label,string,omitempty"`
+	Secret   string  `json:"-"`
+	Dash     string  `json:"-,"`
+	Renamed  string  `json:"name"`
+	Name     string
+	Invalid  string `json:"bad\\name,omitempty"`
+	Left     string `json:"Right"`
+	Right    string `json:"bad\\side,omitempty"`
+	Count    int    `json:",omitempty"`
+	internal string
+}
+
+func Test_JSONTags(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected error
+	}
+
+	tests := []TestITem{
+		{
+			"Valid quoted values",
+			`{"id": "42", "ratio": "0.5", "enabled": "true", "label": "\"text\""}`,
+			nil,
+		},
+		{
+			"Invalid quoted integer, not a string",
+			`{"id": 42}`,
+			validator.ErrInvalidData.Context("id").Value(42),
+		},
+		{
+			"Invalid quoted integer, out of range",
+			`{"id": "0"}`,
+			validator.ErrValueOutOfRange.Context("id").Value(0),
+		},
+		{
+			"Invalid quoted integer, contains a string",
+			`{"id": "\"42\""}`,
+			validator.ErrInvalidData.Context("id").Value(`"42"`),
+		},
+		{
+			"Invalid quoted bool",
+			`{"enabled": "yes"}`,
+			validator.ErrInvalidData.Context("enabled").Value("yes"),
+		},
+		{
+			"Invalid quoted string, not encoded",
+			`{"label": "text"}`,
+			validator.ErrInvalidData.Context("label").Value("text"),
+		},
+		{
+			"Invalid skipped field",
+			`{"Secret": "hidden"}`,
+			validator.ErrInvalidFieldName.Value("Secret"),
+		},
+		{
+			"Valid field named with a dash",
+			`{"-": "dash"}`,
+			nil,
+		},
+		{
+			"Valid tagged field hides untagged field",
+			`{"name": "tagged"}`,
+			nil,
+		},
+		{
+			"Valid field name used for invalid tag name",
+			`{"Invalid": "value", "Count": 3}`,
+			nil,
+		},
+		{
+			"Valid tagged field hides field with invalid tag name",
+			`{"Right": "left"}`,
+			nil,
+		},
+		{
+			"Invalid unexported field",
+			`{"internal": "value"}`,
+			validator.ErrInvalidFieldName.Value("internal"),
+		},
+	}
+
+	item, err := validator.New(&JSONTagObject{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		var (
+			msg1 string
+			msg2 string
+		)
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg1 = err.Error()
+		}
+
+		if test.expected != nil {
+			msg2 = test.expected.Error()
+		}
+
+		if msg1 != msg2 {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+}
+
+func Test_JSONTagsMarshal(t *testing.T) {
+	enabled := true
+
+	// The output of json.Marshal must always be accepted by the validator.
+	b, err := json.Marshal(JSONTagObject{
+		ID:      7,
+		Ratio:   2.5,
+		Enabled: &enabled,
+		Label:   "label",
+		Secret:  "secret",
+		Dash:    "dash",
+		Renamed: "renamed",
+		Name:    "name",
+		Left:    "left",
+		Count:   3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := validator.New(&JSONTagObject{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	if err := item.Validate(string(b)); err != nil {
+		t.Errorf("Validate(%s) unexpected error: %v", b, err)
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+// NamedBytes is a byte slice type without its own marshaler, so it is written
+// as a base64 string like []byte.
+type NamedBytes []byte
+
+// ByteObject has the byte types that encoding/json writes as base64 strings,
+// and a byte array, which is written as an array of numbers.
+type ByteObject struct {
+	Bytes []byte          `json:"bytes" validate:"maxlen=8"`
+	Named NamedBytes      `json:"named"`
+	Fixed [2]byte         `json:"fixed"`
+	Raw   json.RawMessage `json:"raw"`
+}
+
+func Test_ByteSlices(t *testing.T) {
+	// The output of json.Marshal must always be accepted by the validator.
+	b, err := json.Marshal(ByteObject{Bytes: []byte("hi"), Named: NamedBytes{0xff, 0xfe, 0xfd}, Fixed: [2]byte{1, 2}, Raw: json.RawMessage(`[1]`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := validator.New(&ByteObject{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	if err := item.Validate(string(b)); err != nil {
+		t.Errorf("Validate(%s) unexpected error: %v", b, err)
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"bytes": "aGk=", "named": null, "fixed": [1, 255]}`, ""},
+		{`{"bytes": "aGk"}`, `value does not match pattern, in bytes: "aGk", expected ^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{2}==|[A-Za-z0-9+/]{3}=)?$`},
+		{`{"bytes": "aGVsbG8gdGhlcmU="}`, `value length out of range, in bytes: "aGVsbG8gdGhlcmU="`},
+		{`{"named": [104, 105]}`, `invalid data, in named: "[104 105]"`},
+		{`{"fixed": "aGk="}`, `invalid data, in fixed: "aGk="`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Errorf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+func FuzzEmployees(f *testing.F) {
+	item, err := validator.New(&Employees{})
+	if err != nil {
+		f.Fatalf("Failed to define structure: %v", err)
+	}
+
+	validator.Fuzz(f, item)
+}
+
+func FuzzGeneratedObject(f *testing.F) {
+	item, err := validator.New(&GeneratedObject{})
+	if err != nil {
+		f.Fatalf("Failed to define structure: %v", err)
+	}
+
+	validator.Fuzz(f, item)
+}
+
+func FuzzCompiledMap(f *testing.F) {
+	item, err := validator.Compile("map[string: enum=(a,b,c)] []int: base=(minvalue=1)")
+	if err != nil {
+		f.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	// Inputs that are not maps must not cause a panic.
+	f.Add(`"text"`, int64(0))
+	f.Add(`[1, 2, 3]`, int64(0))
+	f.Add(`null`, int64(0))
+
+	validator.Fuzz(f, item)
+}
+
+func TestCheckProperties_Unsatisfiable(t *testing.T) {
+	item, err := validator.Compile(`int: minvalue=5, maxvalue=1`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	// A validator whose rules cannot be satisfied skips the test, rather
+	// than passing without checking the generated documents.
+	var sub *testing.T
+
+	t.Run("unsatisfiable", func(t *testing.T) {
+		sub = t
+		validator.CheckProperties(t, item, `{"not": "an int"}`, 0)
+	})
+
+	if !sub.Skipped() || sub.Failed() {
+		t.Errorf("CheckProperties() expected the test to be skipped")
+	}
+}
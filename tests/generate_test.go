@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tucats/validator"
+)
+
+// Structure with a wide range of rules to generate documents for.
+type GeneratedObject struct {
+	Name     string         `json:"name"     validate:"required,minlen=1,maxlen=20"`
+	Age      int            `json:"age"      validate:"required,min=18,max=65"`
+	Level    uint8          `json:"level"`
+	Ratio    float32        `json:"ratio"    validate:"min=-2.5"`
+	When     time.Time      `json:"when"     validate:"min=2000-01-01T00:00:00Z"`
+	Timeout  time.Duration  `json:"timeout"  validate:"min=1s,max=2h"`
+	Colors   string         `json:"colors"   validate:"list,enum=red|green|blue,minlen=2,maxlen=3"`
+	Counts   map[string]int `json:"counts"   validate:"key=(x,y,z),value=(min=3)"`
+	Priority int            `json:"priority" validate:"enum=1|2|3"`
+	Address  *Address       `json:"address"`
+	Staff    []Person       `json:"staff"    validate:"minlen=1,maxlen=3"`
+}
+
+func TestGenerator(t *testing.T) {
+	type DejaVu struct {
+		Name     string   `json:"name"     validate:"required,minlength=5,maxlength=100"`
+		Children []DejaVu `json:"children" validate:"minlength=0"`
+	}
+
+	objects := []any{&GeneratedObject{}, &Employees{}, &DejaVu{}}
+
+	for _, object := range objects {
+		item, err := validator.New(object)
+		if err != nil {
+			t.Fatalf("Failed to define structure: %v", err)
+		}
+
+		for seed := int64(0); seed < 50; seed++ {
+			g := validator.NewGenerator(seed)
+
+			text, err := g.Valid(item)
+			if err != nil {
+				t.Fatalf("Valid() unexpected error: %v", err)
+			}
+
+			if err := item.Validate(text); err != nil {
+				t.Fatalf("Valid() document with seed %d is not valid: %v\n%s", seed, err, text)
+			}
+
+			docs, err := g.Invalid(item)
+			if err != nil {
+				t.Fatalf("Invalid() unexpected error: %v", err)
+			}
+
+			if len(docs) == 0 {
+				t.Fatalf("Invalid() did not create any documents")
+			}
+
+			for _, doc := range docs {
+				var msg string
+
+				if err := item.Validate(doc.Text); err != nil {
+					msg = err.Error()
+				}
+
+				if msg != doc.Err.Error() {
+					t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+						doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerator_Seed(t *testing.T) {
+	item, err := validator.New(&GeneratedObject{})
+	if err != nil {
+		t.Fatalf("Failed to define structure: %v", err)
+	}
+
+	first, _ := validator.NewGenerator(42).Valid(item)
+	second, _ := validator.NewGenerator(42).Valid(item)
+
+	if first != second {
+		t.Errorf("Valid() with the same seed created different documents\n%s\n%s", first, second)
+	}
+}
+
+func TestGenerator_Unsatisfiable(t *testing.T) {
+	item, err := validator.Compile("string: enum=(red,green), minlen=6")
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if _, err := validator.NewGenerator(1).Valid(item); err == nil {
+		t.Errorf("Valid() expected error for unsatisfiable validator")
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Lookup finds a user by exactly one of its identifiers. The validate tag of
+// the "_" field applies to the structure.
+type Lookup struct {
+	_        struct{} `validate:"oneof=(id,name,email),noneof=(legacy_id)"`
+	ID       int      `json:"id"        validate:"minvalue=1"`
+	Name     string   `json:"name"      validate:"minlen=1"`
+	Email    string   `json:"email"     validate:"minlen=3"`
+	LegacyID int      `json:"legacy_id"`
+	Contact  *Contact `json:"contact"   validate:"anyof=phone|address"`
+}
+
+// Contact must have at least one way to reach the user.
+type Contact struct {
+	Phone   string   `json:"phone"`
+	Address string   `json:"address"`
+	Street  string   `json:"street"`
+	City    string   `json:"city"`
+	_       struct{} `validate:"allof=(street,city)"`
+}
+
+func Test_Groups(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid, one identifier",
+			`{"email": "a@b.c"}`,
+			"",
+		},
+		{
+			"Invalid, no identifiers",
+			`{}`,
+			`exactly one field in group required, expected one of id, name, email`,
+		},
+		{
+			"Invalid, two identifiers",
+			`{"id": 5, "email": "a@b.c"}`,
+			`exactly one field in group required: "id, email", expected one of id, name, email`,
+		},
+		{
+			"Invalid, null identifier",
+			`{"id": 5, "name": null}`,
+			`invalid data, in name`,
+		},
+		{
+			"Invalid, excluded field",
+			`{"id": 5, "legacy_id": 7}`,
+			`fields in group not allowed: "legacy_id"`,
+		},
+		{
+			"Invalid identifier, checked before the group",
+			`{"id": 0, "email": "a@b.c"}`,
+			`value out of range, in id: "0"`,
+		},
+		{
+			"Valid contact",
+			`{"id": 5, "contact": {"phone": "555-1212", "street": "Main", "city": "Springfield"}}`,
+			"",
+		},
+		{
+			"Invalid contact, no phone or address",
+			`{"id": 5, "contact": {"street": "Main", "city": "Springfield"}}`,
+			`at least one field in group required, expected one of phone, address`,
+		},
+		{
+			"Invalid contact, missing city",
+			`{"id": 5, "contact": {"address": "PO Box 1", "street": "Main"}}`,
+			`all fields in group required: "city", expected street, city`,
+		},
+	}
+
+	item, err := validator.New(&Lookup{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_GroupDefinitions(t *testing.T) {
+	_, err := validator.Compile(`{ id int; name string }: oneof=(id,email)`)
+	if err == nil || err.Error() != `invalid field name, in oneof: "email"` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	_, err = validator.Compile(`{ id int: anyof=(a,b); name string }`)
+	if err == nil || err.Error() != `invalid field group, in anyof: "int"` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	item, err := validator.Compile(`{ id int; name string; "e-mail" string }: oneof=(id,name,"e-mail")`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    id int\n    name string\n    \"e-mail\" string\n}: oneof=(id,name,e-mail)\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"name": "x", "e-mail": "y"}`); err == nil || err.Error() != `exactly one field in group required: "name, e-mail", expected one of id, name, e-mail` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object, exactly one of id, name, e-mail\n    id: integer\n    name: string\n    e-mail: string\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Groups can also be added to a validator directly.
+	item = validator.NewType(validator.TypeStruct).
+		AddField(*validator.NewType(validator.TypeInt).SetName("a")).
+		AddField(*validator.NewType(validator.TypeInt).SetName("b")).
+		AddGroup(validator.GroupAnyOf, "a", "b")
+
+	if err := item.Validate(`{}`); err == nil || err.Error() != `at least one field in group required, expected one of a, b` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+var (
+	errBadRange   = errors.New("end must not be before start")
+	errBadReplica = errors.New("too many replicas for a single zone")
+)
+
+// Window checks its decoded values with a Validate() method.
+type Window struct {
+	Start int `json:"start" validate:"required,minvalue=0"`
+	End   int `json:"end"   validate:"required,minvalue=0"`
+}
+
+func (w Window) Validate() error {
+	if w.End < w.Start {
+		return errBadRange
+	}
+
+	return nil
+}
+
+// Deployment checks its JSON object with a ValidateJSON() method.
+type Deployment struct {
+	Replicas int      `json:"replicas" validate:"required,minvalue=1,maxvalue=9"`
+	Zones    []string `json:"zones"`
+	Windows  []Window `json:"windows"`
+}
+
+func (d *Deployment) ValidateJSON(m map[string]any) error {
+	zones, _ := m["zones"].([]any)
+	if replicas, _ := m["replicas"].(float64); replicas > 3 && len(zones) < 2 {
+		return errBadReplica
+	}
+
+	return nil
+}
+
+func Test_SelfValidating(t *testing.T) {
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+		cause    error
+	}
+
+	tests := []TestITem{
+		{
+			"Valid object",
+			`{"replicas": 5, "zones": ["a", "b"], "windows": [{"start": 1, "end": 2}]}`,
+			"",
+			nil,
+		},
+		{
+			"Valid object, whole numbers written as decimals",
+			`{"replicas": 5.0, "zones": ["a", "b"], "windows": [{"start": 1.0, "end": 2e0}]}`,
+			"",
+			nil,
+		},
+		{
+			"Invalid object, rejected by ValidateJSON",
+			`{"replicas": 5, "zones": ["a"]}`,
+			`validation failed (too many replicas for a single zone)`,
+			errBadReplica,
+		},
+		{
+			"Invalid nested object, rejected by Validate",
+			`{"replicas": 1, "windows": [{"start": 3, "end": 2}]}`,
+			`validation failed (end must not be before start)`,
+			errBadRange,
+		},
+		{
+			"Invalid field, checked before Validate",
+			`{"replicas": 1, "windows": [{"start": -1, "end": 2}]}`,
+			`value out of range, in start: "-1"`,
+			nil,
+		},
+	}
+
+	item, err := validator.New(&Deployment{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	// The type is named by its import path, so a type with the same name in
+	// another package does not share its methods.
+	if base := item.BaseType; base == nil || base.TypeName != "github.com/tucats/validator/tests.Deployment" {
+		t.Fatalf("New() unexpected type name: %v", base)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+
+		if test.cause != nil && !errors.Is(err, test.cause) {
+			t.Errorf("In \"%s\", error does not wrap the cause: %v", test.name, err)
+		}
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+func TestInfer(t *testing.T) {
+	samples := []string{
+		`{"id": "0b7c5f1e-3a4e-4c59-9d5c-0c1a3f2a8d11", "kind": "disk", "size": 3, "ratio": 1.5,
+		  "created": "2024-01-02T03:04:05Z", "ttl": "1h30m", "tags": ["x", "y"], "note": null}`,
+		`{"id": "1b7c5f1e-3a4e-4c59-9d5c-0c1a3f2a8d11", "kind": "tape", "size": 12, "ratio": 2,
+		  "created": "2024-03-02T03:04:05Z", "ttl": "10s", "tags": [], "owner": "Tom"}`,
+		`{"id": "2b7c5f1e-3a4e-4c59-9d5c-0c1a3f2a8d11", "kind": "disk", "size": 7, "ratio": 0.5,
+		  "created": "2025-01-02T03:04:05Z", "ttl": "1m", "tags": ["z"]}`,
+	}
+
+	tests := []struct {
+		name    string // description of this test case
+		options validator.InferOptions
+		want    string
+	}{
+		{
+			name:    "default options",
+			options: validator.DefaultInferOptions,
+			want: `{
+    id uuid: required
+    kind string: required, enum=(disk,tape)
+    size int: required
+    ratio float: required
+    created time: required
+    ttl duration: required
+    tags []string: required
+    note any
+    owner string
+}
+`,
+		},
+		{
+			name:    "with bounds and no enums",
+			options: validator.InferOptions{Bounds: true},
+			want: `{
+    id uuid: required
+    kind string: required, minlen=4, maxlen=4
+    size int: required, minvalue=3, maxvalue=12
+    ratio float: required, minvalue=0.5, maxvalue=2
+    created time: required, minvalue="2024-01-02T03:04:05Z", maxvalue="2025-01-02T03:04:05Z"
+    ttl duration: required, minvalue=10s, maxvalue=1h30m0s
+    tags []string: required, minlen=0, maxlen=2, base=(minlen=1, maxlen=1)
+    note any
+    owner string: minlen=3, maxlen=3
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := validator.InferWithOptions(tt.options, samples...)
+			if err != nil {
+				t.Fatalf("InferWithOptions() unexpected error: %v", err)
+			}
+
+			if got := item.Source(); got != tt.want {
+				t.Errorf("InferWithOptions() = %s, want %s", got, tt.want)
+			}
+
+			// Every sample must be valid for the inferred validator.
+			for _, sample := range samples {
+				if err := item.Validate(sample); err != nil {
+					t.Errorf("Validate() unexpected error for sample: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestInfer_Errors(t *testing.T) {
+	if _, err := validator.Infer(); err == nil {
+		t.Errorf("Infer() expected error with no samples")
+	}
+
+	if _, err := validator.Infer(`{"a": 1`); err == nil {
+		t.Errorf("Infer() expected error for incomplete JSON")
+	}
+
+	if _, err := validator.Infer(`{"a": 1} {"a": 2}`); err == nil {
+		t.Errorf("Infer() expected error for trailing JSON")
+	}
+}
+
+func TestInfer_ExactBounds(t *testing.T) {
+	samples := []string{
+		`{"id": 18446744073709551615, "serial": -9223372036854775808, "amount": 0.10000000000000001}`,
+		`{"id": 18446744073709551614, "serial": 9007199254740993, "amount": 0.1}`,
+	}
+
+	item, err := validator.InferWithOptions(validator.InferOptions{Bounds: true}, samples...)
+	if err != nil {
+		t.Fatalf("InferWithOptions() unexpected error: %v", err)
+	}
+
+	want := `{
+    id int: required, minvalue=18446744073709551614, maxvalue=18446744073709551615
+    serial int: required, minvalue=-9223372036854775808, maxvalue=9007199254740993
+    amount float: required, minvalue=0.1, maxvalue=0.10000000000000001
+}
+`
+
+	if got := item.Source(); got != want {
+		t.Errorf("InferWithOptions() = %s, want %s", got, want)
+	}
+
+	for _, sample := range samples {
+		if err := item.Validate(sample); err != nil {
+			t.Errorf("Validate() unexpected error for sample: %v", err)
+		}
+	}
+
+	if err := item.Validate(`{"id": 18446744073709551616, "serial": 0, "amount": 0.1}`); err == nil ||
+		err.Error() != `value out of range, in id: "18446744073709551616"` {
+		t.Errorf("Validate() unexpected result: %v", err)
+	}
+}
@@ -2,7 +2,9 @@ package tests
 
 import (
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/tucats/validator"
 )
 
@@ -19,6 +21,33 @@ type MapStringArray struct {
 	Items map[string][]string `json:"items" validate:"required,enum=key1|key2,base=(enum=value1|value2|value3|value4)"`
 }
 
+// MapPeople has a map of structures, whose values are checked even though
+// there are no rules for the keys.
+type MapPeople struct {
+	People map[string]MapPerson `json:"people"`
+}
+
+type MapPerson struct {
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age"  validate:"minvalue=0"`
+}
+
+// MapCounts limits the number of keys, and the form of each key.
+type MapCounts struct {
+	Counts map[string]int `json:"counts" validate:"minlen=1,maxlen=3,pattern=^[a-z_]+$,key=(minlen=2,maxlen=8)"`
+}
+
+// MapKeyed has maps with keys that are not strings, which encoding/json writes
+// as the text of the key.
+type MapKeyed struct {
+	Orders  map[int]string        `json:"orders"   validate:"key=(minvalue=1)"`
+	Small   map[int8]bool         `json:"small"`
+	ByID    map[uuid.UUID]int     `json:"by_id"`
+	ByColor map[Color]int         `json:"by_color"`
+	Waits   map[time.Duration]int `json:"waits"`
+	Large   map[uint64]int        `json:"large"`
+}
+
 func Test_Maps(t *testing.T) {
 	type TestITem struct {
 		name     string
@@ -94,6 +123,14 @@ func Test_Maps(t *testing.T) {
 			}`,
 			validator.ErrInvalidEnumeratedValue.Value("value5").Expected([]string{"value1", "value2", "value3", "value4"}),
 		},
+		{
+			"invalid map, value is not an object",
+			&MapInts{},
+			`{
+			    "items": [55, 67]
+			}`,
+			validator.ErrInvalidData.Context("items").Value([]any{55, 67}),
+		},
 		{
 			"invalid map, missing required field",
 			&MapStrings{},
@@ -131,3 +168,345 @@ func Test_Maps(t *testing.T) {
 		}
 	}
 }
+
+func Test_MapRules(t *testing.T) {
+	type TestITem struct {
+		name     string
+		object   any
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid map of structures",
+			&MapPeople{},
+			`{"people": {"bob": {"name": "Bob", "age": 30}}}`,
+			"",
+		},
+		{
+			"Invalid map of structures, without key rules",
+			&MapPeople{},
+			`{"people": {"bob": {"age": 30}}}`,
+			`required field missing: "name"`,
+		},
+		{
+			"Valid key counts",
+			&MapCounts{},
+			`{"counts": {"apples": 3, "pears": 5}}`,
+			"",
+		},
+		{
+			"Invalid, too few keys",
+			&MapCounts{},
+			`{"counts": {}}`,
+			`map length out of range, in counts: "0", expected 1`,
+		},
+		{
+			"Invalid, too many keys",
+			&MapCounts{},
+			`{"counts": {"aa": 1, "bb": 2, "cc": 3, "dd": 4}}`,
+			`map length out of range, in counts: "4", expected 3`,
+		},
+		{
+			"Invalid, key too short",
+			&MapCounts{},
+			`{"counts": {"a": 1}}`,
+			`value length out of range, in counts: "a"`,
+		},
+		{
+			"Invalid, key does not match pattern",
+			&MapCounts{},
+			`{"counts": {"Apples": 1}}`,
+			`value does not match pattern, in counts: "Apples", expected ^[a-z_]+$`,
+		},
+		{
+			"Invalid value",
+			&MapCounts{},
+			`{"counts": {"apples": "three"}}`,
+			`invalid data: "three"`,
+		},
+	}
+
+	for _, test := range tests {
+		item, err := validator.New(test.object)
+		if err != nil {
+			t.Fatal("Failed to define structure:", err)
+		}
+
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for _, object := range []any{&MapPeople{}, &MapCounts{}, &MapStrings{}} {
+		item, err := validator.New(object)
+		if err != nil {
+			t.Fatal("Failed to define structure:", err)
+		}
+
+		for seed := range int64(50) {
+			g := validator.NewGenerator(seed)
+
+			docs, err := g.Invalid(item)
+			if err != nil {
+				t.Fatalf("Invalid() unexpected error: %v", err)
+			}
+
+			for _, doc := range docs {
+				var msg string
+
+				if err := item.Validate(doc.Text); err != nil {
+					msg = err.Error()
+				}
+
+				if msg != doc.Err.Error() {
+					t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+						doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+				}
+			}
+
+			validator.CheckProperties(t, item, "", seed)
+		}
+	}
+}
+
+func Test_MapDefinitions(t *testing.T) {
+	item, err := validator.Compile(`map[string: minlen=1, maxlen=4, pattern="^k[0-9]+$", key=(maxlen=3)] string: pattern="^[a-z]{2,4}$"`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "map[string: minlen=1, maxlen=4, pattern=\"^k[0-9]+$\", key=(maxlen=3)] string: pattern=\"^[a-z]{2,4}$\"\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"k1": "ab", "k200": "cd"}`); err == nil || err.Error() != `value length out of range: "k200"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := copied.Validate(`{"k1": "abcde"}`); err == nil || err.Error() != `value does not match pattern: "abcde", expected ^[a-z]{2,4}$` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "map of string, keys matching \"^k[0-9]+$\", keys with length at most 3, between 1 and 4 keys, values matching \"^[a-z]{2,4}$\"\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	if _, err := validator.Compile(`map[string: pattern="a("] int`); err == nil || err.Error() != `invalid pattern, in pattern: "a(" (error parsing regexp: missing closing ): `+"`a(`"+`)` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	// Key rules can also be set directly.
+	item = validator.NewType(validator.TypeMap).
+		SetKeyType(validator.NewType(validator.TypeString).SetPattern("^[A-Z]+$")).
+		SetMinLength(1)
+
+	if err := item.Validate(`{"ok": 1}`); err == nil || err.Error() != `value does not match pattern: "ok", expected ^[A-Z]+$` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+}
+
+// MapHolder has maps inside an array and a pointer, each with its own rules.
+type MapHolder struct {
+	Lists  []map[string]int  `json:"lists"  validate:"minlen=1,base=(base=(minvalue=3))"`
+	Ref    *map[string]int   `json:"ref"    validate:"required"`
+	Unique []map[string]any  `json:"unique" validate:"unique"`
+	Tuple  [1]map[string]int `json:"tuple"`
+}
+
+func Test_NestedMapSource(t *testing.T) {
+	item, err := validator.New(&MapHolder{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	// The rules of the array or pointer are kept apart from the rules of the
+	// map values, so they are not moved to the values when the source is
+	// compiled again.
+	source := item.Source()
+	if expected := "*{\n    lists [](map[string: nullable] int: minvalue=3): nullable, minlen=1\n" +
+		"    ref *(map[string: nullable] int): required\n    unique [](map[string: nullable] any): nullable, unique\n" +
+		"    tuple [](map[string: nullable] int): minlen=1, maxlen=1\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	compiled, err := validator.Compile(source)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if compiled.Source() != source {
+		t.Fatalf("Compile() unexpected source:\n%s", compiled.Source())
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"lists": [{"a": 3}], "ref": {"b": 1}, "unique": [{"a": 1}, {"a": 2}]}`, ""},
+		{`{"lists": [], "ref": {}}`, `array length out of range, in lists: "0", expected 1`},
+		{`{"lists": [{"a": 2}], "ref": {}}`, `value out of range: "2"`},
+		{`{"lists": [{"a": 3}]}`, `required field missing: "ref"`},
+		{`{"ref": {}, "unique": [{"a": 1}, {"a": 1}]}`, `duplicate array value, in unique: "1"`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := compiled.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	// A tuple with a single map position ends with a separator, since a map
+	// alone in parentheses is a group.
+	tuple, err := validator.Compile(`(map[string] int,)`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if source := tuple.Source(); source != "(map[string] int,)\n" {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+}
+
+func Test_MapKeyTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid keys",
+			`{"orders": {"1": "a", "20": "b"}, "small": {"-128": true}, "by_id": {"6ba7b810-9dad-11d1-80b4-00c04fd430c8": 1},
+			  "by_color": {"red": 1, "green": 2}, "waits": {"1000000000": 1}}`,
+			"",
+		},
+		{
+			"Invalid integer key",
+			`{"orders": {"one": "a"}}`,
+			`invalid data, in orders: "one"`,
+		},
+		{
+			"Integer key out of range",
+			`{"orders": {"0": "a"}}`,
+			`value out of range, in orders: "0"`,
+		},
+		{
+			"Integer key out of range for the Go type",
+			`{"small": {"128": true}}`,
+			`value out of range, in small: "128"`,
+		},
+		{
+			"Integer key, the largest uint64",
+			`{"large": {"18446744073709551615": 1}}`,
+			"",
+		},
+		{
+			"Integer key out of range for uint64",
+			`{"large": {"18446744073709551616": 1}}`,
+			`value out of range, in large: "18446744073709551616"`,
+		},
+		{
+			"Invalid UUID key",
+			`{"by_id": {"abc": 1}}`,
+			`invalid data, in by_id: "abc"`,
+		},
+		{
+			"Invalid text key",
+			`{"by_color": {"blue": 1}}`,
+			`invalid data, in by_color: "blue" (unknown color)`,
+		},
+		{
+			"Duration keys are integers",
+			`{"waits": {"1s": 1}}`,
+			`invalid data, in waits: "1s"`,
+		},
+	}
+
+	item, err := validator.New(&MapKeyed{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+
+	// The key types are kept in the definition language and JSON forms.
+	compiled, err := validator.Compile(`{ orders map[int: key=(minvalue=1)] string; by_id map[uuid] int }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if source, expected := compiled.Source(), "{\n    orders map[int: key=(minvalue=1)] string\n    by_id map[uuid] int\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(compiled.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"orders": {"-5": "x"}}`); err == nil || err.Error() != `value out of range, in orders: "-5"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object\n    orders: map of integer keys to string, keys at least 1\n    by_id: map of UUID keys to integer\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	if _, err := validator.Compile(`map[float] int`); err == nil || err.Error() != `unsupported type, in line 1, column 5: "float", expected one of string, int, uuid, time, custom` {
+		t.Fatalf("Compile() unexpected result: %v", err)
+	}
+
+	if _, err := validator.New(map[float64]int{}); err == nil || err.Error() != `unsupported type, in key: "float64"` {
+		t.Fatalf("New() unexpected result: %v", err)
+	}
+}
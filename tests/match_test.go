@@ -0,0 +1,163 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+type MatchAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"   validate:"namematch=exact"`
+}
+
+type MatchObject struct {
+	FirstName string         `json:"firstName" validate:"required"`
+	LastName  string         `json:"lastName"  validate:"namematch=compact"`
+	Addresses []MatchAddress `json:"addresses" validate:"fieldmatch=fold"`
+}
+
+func Test_FieldMatch(t *testing.T) {
+	err := validator.RegisterNormalizer("compact", func(name string) string {
+		return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type TestITem struct {
+		name     string
+		mode     string
+		jsonText string
+		expected error
+	}
+
+	tests := []TestITem{
+		{
+			"Valid exact names",
+			validator.MatchExact,
+			`{"firstName": "Tom", "lastName": "Smith"}`,
+			nil,
+		},
+		{
+			"Invalid folded name in exact mode",
+			validator.MatchExact,
+			`{"FirstName": "Tom"}`,
+			validator.ErrInvalidFieldName.Value("FirstName"),
+		},
+		{
+			"Valid folded name",
+			validator.MatchFold,
+			`{"FIRSTNAME": "Tom"}`,
+			nil,
+		},
+		{
+			"Valid default mode is exact",
+			"",
+			`{"firstName": "Tom", "last_name": "Smith"}`,
+			nil,
+		},
+		{
+			"Valid field normalizer",
+			validator.MatchExact,
+			`{"firstName": "Tom", "LAST-NAME": "Smith"}`,
+			nil,
+		},
+		{
+			"Invalid keys matching the same field",
+			validator.MatchFold,
+			`{"firstName": "Tom", "FirstName": "Tom"}`,
+			validator.ErrFieldNameCollision.Value("FirstName").Expected("firstName"),
+		},
+		{
+			"Invalid folded keys matching the same field",
+			validator.MatchFold,
+			`{"FIRSTNAME": "Tom", "FirstName": "Tom"}`,
+			validator.ErrFieldNameCollision.Value("FirstName").Expected("firstName"),
+		},
+		{
+			"Valid nested structure set by tag",
+			validator.MatchExact,
+			`{"firstName": "Tom", "addresses": [{"Street": "Main"}]}`,
+			nil,
+		},
+		{
+			"Invalid nested field with exact name override",
+			validator.MatchExact,
+			`{"firstName": "Tom", "addresses": [{"City": "Springfield"}]}`,
+			validator.ErrInvalidFieldName.Value("City"),
+		},
+	}
+
+	for _, test := range tests {
+		var (
+			msg1 string
+			msg2 string
+		)
+
+		item, err := validator.New(&MatchObject{})
+		if err != nil {
+			t.Fatal("Failed to define structure:", err)
+		}
+
+		err = item.SetFieldMatch(test.mode).Validate(test.jsonText)
+		if err != nil {
+			msg1 = err.Error()
+		}
+
+		if test.expected != nil {
+			msg2 = test.expected.Error()
+		}
+
+		if msg1 != msg2 {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+}
+
+func Test_FieldMatchAll(t *testing.T) {
+	item, err := validator.Compile(`{ name string: required; address { city string } }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	text := `{"Name": "Tom", "Address": {"CITY": "Springfield"}}`
+
+	if err := item.Validate(text); err == nil {
+		t.Errorf("Validate() expected error for exact field names")
+	}
+
+	item.SetFieldMatchAll(validator.MatchFold)
+
+	if err := item.Validate(text); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	if err := validator.RegisterNormalizer(validator.MatchFold, strings.ToUpper); err == nil {
+		t.Errorf("RegisterNormalizer() expected error replacing a built-in mode")
+	}
+
+	if err := item.ParseTag("fieldmatch=unknown"); err == nil {
+		t.Errorf("ParseTag() expected error for unknown mode")
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_FieldMatchCollision(t *testing.T) {
+	item, err := validator.Compile(`{ name string: required }: fieldmatch=fold, foreignkeys`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	// Both keys are decoded into the same field by encoding/json, so they
+	// collide even when one of them matches the field name exactly and
+	// foreign keys are allowed.
+	err = item.Validate(`{"name": "Tom", "NAME": 12}`)
+	if err == nil || err.Error() != `more than one key for field: "NAME", expected name` {
+		t.Errorf("Validate() unexpected result: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Profile has pointer fields, which can be null, and a string field that can
+// only be null because of its nullable rule.
+type Profile struct {
+	Name     *string  `json:"name"     validate:"required,minlen=3"`
+	Age      *int     `json:"age"      validate:"minvalue=1"`
+	Nickname string   `json:"nickname" validate:"nullable,maxlen=8"`
+	Email    string   `json:"email"`
+	Address  *Address `json:"address"`
+}
+
+func Test_Nullable(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid, all values",
+			`{"name": "Tom", "age": 4, "nickname": "T", "email": "t@x.com"}`,
+			"",
+		},
+		{
+			"Valid, null pointers and nullable field",
+			`{"name": null, "age": null, "nickname": null, "address": null}`,
+			"",
+		},
+		{
+			"Invalid, absent required pointer",
+			`{"age": 4}`,
+			`required field missing: "name"`,
+		},
+		{
+			"Invalid, null field that is not nullable",
+			`{"name": "Tom", "email": null}`,
+			`invalid data, in email`,
+		},
+		{
+			"Invalid pointer value, rule from the tag",
+			`{"name": "To"}`,
+			`value length out of range: "To"`,
+		},
+		{
+			"Invalid pointer value, out of range",
+			`{"name": "Tom", "age": 0}`,
+			`value out of range: "0"`,
+		},
+		{
+			"Invalid nullable value",
+			`{"name": "Tom", "nickname": "much too long"}`,
+			`value length out of range, in nickname: "much too long"`,
+		},
+	}
+
+	item, err := validator.New(&Profile{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_NullableDefinitions(t *testing.T) {
+	item, err := validator.Compile(`{ name string: nullable, required; count *int: minvalue=1; tags []string: base=(nullable) }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    name string: required, nullable\n    count *int: base=(minvalue=1)\n    tags []string: base=(nullable)\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"name": null, "count": null, "tags": ["a", null]}`, ""},
+		{`{"count": 2}`, `required field missing: "name"`},
+		{`{"name": "a", "count": 0}`, `value out of range: "0"`},
+		{`{"name": "a", "tags": null}`, `invalid data, in tags`},
+		{`null`, `invalid data`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := copied.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	if text := copied.Describe(); text != "object\n    name: required nullable string\n    count: integer, at least 1\n    tags: array of nullable string\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// A pointer created by New() accepts a null value.
+	pointer, err := validator.New(new(int))
+	if err != nil {
+		t.Fatalf("New() unexpected error: %v", err)
+	}
+
+	if err := pointer.Validate(`null`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeString).SetNullable(true).Validate(`null`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+}
+
+// Holder has fields whose Go values can be nil, or that decode null themselves.
+type Holder struct {
+	Items   []int             `json:"items"`
+	Labels  map[string]string `json:"labels"`
+	Extra   any               `json:"extra"`
+	Address netip.Addr        `json:"address"`
+	Raw     json.RawMessage   `json:"raw"`
+	Count   int               `json:"count"`
+}
+
+func Test_NullableGoValues(t *testing.T) {
+	item, err := validator.New(&Holder{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	// The zero value has nil slices, maps, and interfaces, which are written
+	// as null.
+	b, err := json.Marshal(Holder{})
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+
+	if err := item.Validate(string(b)); err != nil {
+		t.Fatalf("Validate(%s) unexpected error: %v", b, err)
+	}
+
+	if err := item.Validate(`{"address": null, "raw": null}`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	// A null value for a field that is not nullable has its own error, with the
+	// same text as other invalid data.
+	err = item.Validate(`{"count": null}`)
+	if !errors.Is(err, validator.ErrNullNotAllowed) || errors.Is(err, validator.ErrInvalidData) {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err.Error() != `invalid data, in count` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+}
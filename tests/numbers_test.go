@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Account has exact integer identifiers and decimal amounts of money.
+type Account struct {
+	ID      uint64      `json:"id"      validate:"required"`
+	Serial  int64       `json:"serial"`
+	Count   int32       `json:"count"   validate:"minvalue=1"`
+	Balance float64     `json:"balance" validate:"decimal,minvalue=0,maxvalue=99999999999999999.99,multipleof=0.01"`
+	Fee     json.Number `json:"fee"     validate:"lt=100"`
+}
+
+func Test_Numbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid account",
+			`{"id": 18446744073709551615, "serial": -9223372036854775808, "count": 3, "balance": 99999999999999999.99, "fee": 99.99}`,
+			"",
+		},
+		{
+			"Valid count, a whole number written with a fraction and an exponent",
+			`{"id": 1, "count": 3.0, "serial": 1e3}`,
+			"",
+		},
+		{
+			"Invalid id, above the largest uint64",
+			`{"id": 18446744073709551616}`,
+			`value out of range, in id: "18446744073709551616"`,
+		},
+		{
+			"Invalid id, negative",
+			`{"id": -1}`,
+			`value out of range, in id: "-1"`,
+		},
+		{
+			"Invalid serial, one more than the largest int64",
+			`{"id": 1, "serial": 9223372036854775808}`,
+			`value out of range, in serial: "9223372036854775808"`,
+		},
+		{
+			"Invalid count, a fraction is not truncated",
+			`{"id": 1, "count": 3.7}`,
+			`invalid data, in count: "3.7"`,
+		},
+		{
+			"Invalid count, above the largest int32",
+			`{"id": 1, "count": 2147483648}`,
+			`value out of range, in count: "2147483648"`,
+		},
+		{
+			"Invalid balance, a fraction of a cent",
+			`{"id": 1, "balance": 10.005}`,
+			`value is not a multiple of the step, in balance: "10.005", expected multiple of 0.01`,
+		},
+		{
+			"Invalid balance, above the maximum by one cent",
+			`{"id": 1, "balance": 100000000000000000.00}`,
+			`value out of range, in balance: "100000000000000000.00"`,
+		},
+		{
+			"Invalid fee, equal to the exclusive maximum",
+			`{"id": 1, "fee": 100.0}`,
+			`value out of range, in fee: "100.0", expected less than 100`,
+		},
+	}
+
+	item, err := validator.New(&Account{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_NumberDefinitions(t *testing.T) {
+	item, err := validator.Compile(`{ price float: decimal, gt=0, multipleof=0.05; ids []int: unique; big int: maxvalue=9007199254740993 }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    price float: gt=0, multipleof=0.05, decimal\n    ids []int: unique\n    big int: maxvalue=9007199254740993\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if copied.Source() != source {
+		t.Fatalf("NewJSON() unexpected source:\n%s", copied.Source())
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"price": 0.35, "ids": [1, 2], "big": 9007199254740993}`, ""},
+		{`{"price": 0.3000000000000001}`, `value is not a multiple of the step, in price: "0.3000000000000001", expected multiple of 0.05`},
+		{`{"ids": [1, 2, 1.0]}`, `duplicate array value, in ids: "2"`},
+		{`{"big": 9007199254740994}`, `value out of range, in big: "9007199254740994"`},
+		{`{"big": 1e999999999}`, `invalid data, in big: "1e999999999"`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := copied.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	if text := copied.Describe(); text != "object\n    price: exact decimal number, greater than 0, multiple of 0.05\n"+
+		"    ids: array of integer, unique values\n    big: integer, at most 9007199254740993\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Setting the rules directly. The same limit is rounded when the value is
+	// checked as a float64.
+	api := validator.NewType(validator.TypeFloat).SetMaxValue("0.1").SetDecimal(true)
+
+	if err := api.Validate(`0.10000000000000001`); !errors.Is(err, validator.ErrValueOutOfRange) {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := api.SetDecimal(false).Validate(`0.10000000000000001`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeString).ParseTag("decimal"); err == nil ||
+		err.Error() != `keyword only valid with numeric type, in decimal: "string"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "int", "decimal": true}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for decimal on an integer")
+	}
+}
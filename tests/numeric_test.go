@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Order has numbers with exclusive limits, steps, and enumerated values.
+type Order struct {
+	Price    float64 `json:"price"    validate:"required,gt=0,lt=1000,multipleof=0.01"`
+	Quantity int     `json:"quantity" validate:"required,minvalue=1,multipleof=6"`
+	Discount float64 `json:"discount" validate:"enum=0|0.05|0.1|0.25"`
+	Rating   float64 `json:"rating"   validate:"gt=0,maxvalue=5"`
+}
+
+func Test_NumericRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid order",
+			`{"price": 19.99, "quantity": 12, "discount": 0.1, "rating": 5}`,
+			"",
+		},
+		{
+			"Valid price, a multiple of the step within the tolerance",
+			`{"price": 0.3, "quantity": 6}`,
+			"",
+		},
+		{
+			"Invalid price, equal to the exclusive minimum",
+			`{"price": 0, "quantity": 6}`,
+			`value out of range, in price: "0", expected greater than 0`,
+		},
+		{
+			"Invalid price, equal to the exclusive maximum",
+			`{"price": 1000, "quantity": 6}`,
+			`value out of range, in price: "1000", expected less than 1000`,
+		},
+		{
+			"Invalid price, not a multiple of the step",
+			`{"price": 19.999, "quantity": 6}`,
+			`value is not a multiple of the step, in price: "19.999", expected multiple of 0.01`,
+		},
+		{
+			"Invalid quantity, below the inclusive minimum",
+			`{"price": 1, "quantity": 0}`,
+			`value out of range, in quantity: "0"`,
+		},
+		{
+			"Invalid quantity, not a multiple of the step",
+			`{"price": 1, "quantity": 8}`,
+			`value is not a multiple of the step, in quantity: "8", expected multiple of 6`,
+		},
+		{
+			"Invalid discount, not an enumerated value",
+			`{"price": 1, "quantity": 6, "discount": 0.2}`,
+			`invalid enumerated value, in discount: "0.2", expected one of 0, 0.05, 0.1, 0.25`,
+		},
+		{
+			"Invalid rating, above the inclusive maximum",
+			`{"price": 1, "quantity": 6, "rating": 5.5}`,
+			`value out of range, in rating: "5.5"`,
+		},
+	}
+
+	item, err := validator.New(&Order{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+}
+
+func Test_NumericDefinitions(t *testing.T) {
+	item, err := validator.Compile(`{ ratio float: gt=0, lt=1; count int: gt=-1, maxvalue=100, multipleof=5; size float: enum=(1.5,2.5) }`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    ratio float: gt=0, lt=1\n    count int: gt=-1, maxvalue=100, multipleof=5\n    size float: enum=(1.5,2.5)\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if copied.Source() != source {
+		t.Fatalf("NewJSON() unexpected source:\n%s", copied.Source())
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`{"ratio": 0.5, "count": 0, "size": 2.5}`, ""},
+		{`{"ratio": 1}`, `value out of range, in ratio: "1", expected less than 1`},
+		{`{"count": -1}`, `value out of range, in count: "-1", expected greater than -1`},
+		{`{"count": 7}`, `value is not a multiple of the step, in count: "7", expected multiple of 5`},
+		{`{"size": 2}`, `invalid enumerated value, in size: "2", expected one of 1.5, 2.5`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := copied.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	if text := copied.Describe(); text != "object\n    ratio: number, greater than 0 and less than 1\n"+
+		"    count: integer, greater than -1 and at most 100, multiple of 5\n    size: number, one of 1.5, 2.5\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Setting the rules directly. A later inclusive limit replaces an
+	// exclusive one.
+	api := validator.NewType(validator.TypeFloat).SetLessThan(10).SetMultipleOf(0.5)
+
+	if err := api.Validate(`9.5`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := api.Validate(`10`); !errors.Is(err, validator.ErrValueOutOfRange) {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := api.SetMaxValue(10).Validate(`10`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeString).ParseTag("gt=1"); err == nil ||
+		err.Error() != `keyword only valid with numeric type, in gt: "string"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeInt).ParseTag("multipleof=0"); err == nil {
+		t.Fatal("ParseTag() expected an error for a step of zero")
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "string", "multiple_of": 2}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for a step on a string")
+	}
+}
@@ -0,0 +1,186 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Extensible is an object that allows extension keys starting with "x-", and
+// string labels for any other key.
+type Extensible struct {
+	_    struct{} `validate:"property=('^x-' int: minvalue=0),additional=(string: maxlen=8)"`
+	Name string   `json:"name" validate:"required"`
+}
+
+// Tagged has a field with an object that only allows keys matching a pattern.
+type Tagged struct {
+	Labels *Labels `json:"labels" validate:"property=(\"^[a-z]+$\" string: minlen=1)"`
+}
+
+// Labels is an object with no fields.
+type Labels struct{}
+
+func Test_Properties(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     any
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid extension and label",
+			&Extensible{},
+			`{"name": "a", "x-rate": 5, "color": "red"}`,
+			"",
+		},
+		{
+			"Invalid extension value",
+			&Extensible{},
+			`{"name": "a", "x-rate": -1}`,
+			`value out of range: "-1"`,
+		},
+		{
+			"Invalid additional value",
+			&Extensible{},
+			`{"name": "a", "color": "very long text"}`,
+			`value length out of range: "very long text"`,
+		},
+		{
+			"Fields are not checked as additional properties",
+			&Extensible{},
+			`{"name": "a long name"}`,
+			"",
+		},
+		{
+			"Valid labels",
+			&Tagged{},
+			`{"labels": {"env": "prod"}}`,
+			"",
+		},
+		{
+			"Invalid label key",
+			&Tagged{},
+			`{"labels": {"Env": "prod"}}`,
+			`invalid field name: "Env"`,
+		},
+		{
+			"Invalid label value",
+			&Tagged{},
+			`{"labels": {"env": ""}}`,
+			`value length out of range`,
+		},
+	}
+
+	for _, test := range tests {
+		item, err := validator.New(test.item)
+		if err != nil {
+			t.Fatalf("In \"%s\", failed to define structure: %v", test.name, err)
+		}
+
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for _, value := range []any{&Extensible{}, &Tagged{}} {
+		item, err := validator.New(value)
+		if err != nil {
+			t.Fatal("Failed to define structure:", err)
+		}
+
+		for seed := range int64(50) {
+			g := validator.NewGenerator(seed)
+
+			docs, err := g.Invalid(item)
+			if err != nil {
+				t.Fatalf("Invalid() unexpected error: %v", err)
+			}
+
+			for _, doc := range docs {
+				var msg string
+
+				if err := item.Validate(doc.Text); err != nil {
+					msg = err.Error()
+				}
+
+				if msg != doc.Err.Error() {
+					t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+						doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+				}
+			}
+
+			validator.CheckProperties(t, item, "", seed)
+		}
+	}
+}
+
+func Test_PropertyDefinitions(t *testing.T) {
+	item, err := validator.Compile(`{ name string }: property=("^x-" int: minvalue=0, maxvalue=9), additional=({ id int: required })`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    name string\n}: property=(\"^x-\" int: minvalue=0, maxvalue=9), additional=({\n    id int: required\n})\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	compiled, err := validator.Compile(source)
+	if err != nil {
+		t.Fatalf("Compile() of source unexpected error: %v", err)
+	}
+
+	copied, err := validator.NewJSON([]byte(compiled.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if err := copied.Validate(`{"name": "a", "x-n": 10}`); err == nil || err.Error() != `value out of range: "10"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := copied.Validate(`{"name": "a", "other": {}}`); err == nil || err.Error() != `required field missing: "id"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := copied.Validate(`{"name": "a", "x-n": 1, "other": {"id": 1}}`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if text := copied.Describe(); text != "object, other fields matching \"^x-\" are integer, between 0 and 9, other fields are object\n    name: string\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Setting the rules directly.
+	api := validator.NewType(validator.TypeStruct).
+		AddPatternProperty("^x-", nil).
+		SetAdditionalProperties(validator.NewType(validator.TypeBool))
+
+	if err := api.Validate(`{"x-any": [1, 2], "flag": true}`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := api.Validate(`{"flag": "yes"}`); err == nil || err.Error() != `invalid data: "yes"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeInt).ParseTag("additional=(string)"); err == nil ||
+		err.Error() != `keyword only valid with struct type, in additional: "int"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeStruct).ParseTag("property=(^x- string)"); err == nil ||
+		err.Error() != `invalid pattern, in property: "(^x- string)"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "struct", "pattern_properties": [{"pattern": "(", "value": {"type": "int"}}]}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Decimal is an exact decimal number, written in JSON as a string.
+type Decimal struct {
+	value big.Rat
+}
+
+// decimalHandler validates Decimal values.
+type decimalHandler struct{}
+
+func (decimalHandler) Name() string {
+	return "decimal"
+}
+
+func (decimalHandler) Shape() validator.Type {
+	return validator.TypeString
+}
+
+func (decimalHandler) Value(v any) (any, error) {
+	r, ok := new(big.Rat).SetString(fmt.Sprintf("%v", v))
+	if !ok {
+		return nil, errors.New("not a decimal number")
+	}
+
+	return r, nil
+}
+
+func (decimalHandler) Compare(a, b any) int {
+	return a.(*big.Rat).Cmp(b.(*big.Rat))
+}
+
+type Invoice struct {
+	Total    Decimal `json:"total"    validate:"required,minvalue=0.01,maxvalue=1000"`
+	Discount Decimal `json:"discount" validate:"enum=0|0.05|0.1"`
+}
+
+func Test_RegisterType(t *testing.T) {
+	if err := validator.RegisterType(reflect.TypeFor[Decimal](), decimalHandler{}); err != nil {
+		t.Fatalf("RegisterType() unexpected error: %v", err)
+	}
+
+	type TestITem struct {
+		name     string
+		jsonText string
+		expected string
+	}
+
+	tests := []TestITem{
+		{
+			"Valid decimal values",
+			`{"total": "999.99", "discount": "0.10"}`,
+			"",
+		},
+		{
+			"Invalid decimal, wrong shape",
+			`{"total": true}`,
+			`invalid data, in total: "true"`,
+		},
+		{
+			"Invalid decimal, not a number",
+			`{"total": "ten"}`,
+			`invalid data, in total: "ten" (not a decimal number)`,
+		},
+		{
+			"Invalid decimal, too small",
+			`{"total": "0.001"}`,
+			`value out of range, in total: "0.001"`,
+		},
+		{
+			"Invalid decimal, too large",
+			`{"total": "1000.01"}`,
+			`value out of range, in total: "1000.01"`,
+		},
+		{
+			"Invalid decimal, not an enumerated value",
+			`{"total": "1", "discount": "0.2"}`,
+			`invalid enumerated value, in discount: "0.2", expected one of 0, 0.05, 0.1`,
+		},
+	}
+
+	item, err := validator.New(&Invoice{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+
+	for seed := range int64(20) {
+		validator.CheckProperties(t, item, "", seed)
+	}
+
+	// The JSON form of the validator uses the registered name of the type.
+	text := item.String()
+
+	copied, err := validator.NewJSON([]byte(text))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(item, copied) {
+		t.Errorf("NewJSON() = %v, want %v", copied, item)
+	}
+
+	compiled, err := validator.Compile(`total custom decimal: minvalue=1`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if err := compiled.Validate(`"0.5"`); err == nil {
+		t.Errorf("Validate() expected error for value out of range")
+	}
+
+	if err := validator.RegisterType(reflect.TypeFor[Decimal](), validatorNamed("string")); err == nil {
+		t.Errorf("RegisterType() expected error for built-in type name")
+	}
+
+	// A type has only one handler, so the handler used for it is always the
+	// same. Registering the same name again replaces the handler.
+	if err := validator.RegisterType(reflect.TypeFor[Decimal](), validatorNamed("money")); err == nil ||
+		err.Error() != `name already exists, in money: "decimal"` {
+		t.Errorf("RegisterType() unexpected result: %v", err)
+	}
+
+	if err := validator.RegisterType(reflect.TypeFor[Decimal](), decimalHandler{}); err != nil {
+		t.Errorf("RegisterType() unexpected error: %v", err)
+	}
+}
+
+// validatorNamed is a handler with the given name.
+type validatorNamed string
+
+func (v validatorNamed) Name() string           { return string(v) }
+func (validatorNamed) Shape() validator.Type    { return validator.TypeString }
+func (validatorNamed) Value(v any) (any, error) { return v, nil }
+func (validatorNamed) Compare(a, b any) int     { return 0 }
+
+// Celsius is a temperature, written in JSON as a number.
+type Celsius float64
+
+// celsiusHandler validates Celsius values, which are float64 values in JSON.
+type celsiusHandler struct{}
+
+func (celsiusHandler) Name() string {
+	return "celsius"
+}
+
+func (celsiusHandler) Shape() validator.Type {
+	return validator.TypeFloat
+}
+
+func (celsiusHandler) Value(v any) (any, error) {
+	switch actual := v.(type) {
+	case float64:
+		return actual, nil
+
+	case string:
+		return strconv.ParseFloat(actual, 64)
+	}
+
+	return nil, fmt.Errorf("unexpected value %T", v)
+}
+
+func (celsiusHandler) Compare(a, b any) int {
+	return cmp.Compare(a.(float64), b.(float64))
+}
+
+type Reading struct {
+	Temperature Celsius `json:"temperature" validate:"required,minvalue=-273.15"`
+}
+
+func Test_RegisterNumericType(t *testing.T) {
+	if err := validator.RegisterType(reflect.TypeFor[Celsius](), celsiusHandler{}); err != nil {
+		t.Fatalf("RegisterType() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{"Valid temperature", `{"temperature": 21.5}`, ""},
+		{"Valid integer temperature", `{"temperature": -40}`, ""},
+		{"Invalid temperature, too cold", `{"temperature": -300}`, `value out of range, in temperature: "-300"`},
+		{"Invalid temperature, wrong shape", `{"temperature": "warm"}`, `invalid data, in temperature: "warm"`},
+	}
+
+	item, err := validator.New(&Reading{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	for _, test := range tests {
+		msg := ""
+
+		err = item.Validate(test.jsonText)
+		if err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %v\n", test.name, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+func TestItem_Source(t *testing.T) {
+	tests := []struct {
+		name string // description of this test case
+		src  string
+		want string
+	}{
+		{
+			name: "simple integer type",
+			src:  "int: required, minvalue=1, maxvalue=10",
+			want: "int: required, minvalue=1, maxvalue=10\n",
+		},
+		{
+			name: "time values are quoted",
+			src:  `time: minvalue=2020-01-01T00:00:00Z, maxvalue="2030-01-01T00:00:00Z"`,
+			want: "time: minvalue=\"2020-01-01T00:00:00Z\", maxvalue=\"2030-01-01T00:00:00Z\"\n",
+		},
+		{
+			name: "object with enum list",
+			src: `person {
+					name string: required, enum=(Tom, "Mary Ann")
+					"map" int: minvalue=-5
+				  }`,
+			want: `person {
+    name string: required, enum=(Tom,"Mary Ann")
+    "map" int: minvalue=-5
+}
+`,
+		},
+		{
+			name: "map of arrays",
+			src:  "map[string: required, enum=(a,b)] []string: minlen=1, base=(enum=x|y)",
+			want: "map[string: required, enum=(a,b)] []string: minlen=1, base=(enum=(x,y))\n",
+		},
+		{
+			name: "array of pointers to objects",
+			src: `staff []*{
+			         name string
+			      }: minlen=1`,
+			want: `staff []*{
+    name string
+}: minlen=1
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := validator.Compile(tt.src)
+			if err != nil {
+				t.Fatalf("Compile() unexpected error: %v", err)
+			}
+
+			got := item.Source()
+			if got != tt.want {
+				t.Errorf("Source() = %q, want %q", got, tt.want)
+			}
+
+			// The source text must compile back to the same validator.
+			again, err := validator.Compile(got)
+			if err != nil {
+				t.Fatalf("Compile() of source unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(item, again) {
+				t.Errorf("Compile(Source()) = %v, want %v", again, item)
+			}
+		})
+	}
+}
+
+func Test_SourceRoundTrip(t *testing.T) {
+	// Create a validator for the Employees structure.
+	i1, err := validator.New(&Employees{})
+	if err != nil {
+		t.Fatalf("Unexpected error creating validator: %s", err)
+	}
+
+	// Convert it to source text, and compile that text.
+	i2, err := validator.Compile(i1.Source())
+	if err != nil {
+		t.Fatalf("Unexpected error compiling validator source: %s\n%s", err, i1.Source())
+	}
+
+	// Both validators must report the same result for the same JSON.
+	texts := []string{
+		`{"department": "Research", "division": "HR", "staff": [{"name": "Tom", "age": 44, "address": {"street": "1 Main", "city": "Cary"}}]}`,
+		`{"department": "Research", "division": "Legal"}`,
+		`{"department": "Research", "division": "HR", "staff": [{"name": "Tom", "age": 14, "address": {"street": "1 Main", "city": "Cary"}}]}`,
+		`{"department": "Research", "division": "HR", "staff": []}`,
+		`{"division": "HR"}`,
+	}
+
+	for _, text := range texts {
+		var m1, m2 string
+
+		if err := i1.Validate(text); err != nil {
+			m1 = err.Error()
+		}
+
+		if err := i2.Validate(text); err != nil {
+			m2 = err.Error()
+		}
+
+		if m1 != m2 {
+			t.Errorf("Validate(%s) results differ\n  original:  %s\n  recompiled: %s", text, m1, m2)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	got, err := validator.Format("{ name string: required; age int }")
+	if err != nil {
+		t.Fatalf("Format() unexpected error: %v", err)
+	}
+
+	want := "{\n    name string: required\n    age int\n}\n"
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+
+	_, err = validator.Format("{ name string")
+	if err == nil {
+		t.Errorf("Format() expected error for incomplete source")
+	}
+}
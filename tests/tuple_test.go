@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Place has a fixed-size Go array, which must have exactly two values.
+type Place struct {
+	Name     string     `json:"name"     validate:"required"`
+	Location [2]float64 `json:"location" validate:"required,value=(minvalue=-180,maxvalue=180)"`
+}
+
+// The reading is a tuple of a timestamp, a value, and a unit, followed by
+// any number of string labels.
+const readingDefinition = `{
+	sensor string: required
+	reading (time; float: minvalue=0; string: enum=C|F): additional=(string: minlen=1), required
+}`
+
+func Test_Tuple(t *testing.T) {
+	place, err := validator.New(&Place{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	reading, err := validator.Compile(readingDefinition)
+	if err != nil {
+		t.Fatal("Failed to compile definition:", err)
+	}
+
+	tests := []struct {
+		name     string
+		item     *validator.Item
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid location",
+			place,
+			`{"name": "home", "location": [51.5, -0.12]}`,
+			"",
+		},
+		{
+			"Invalid location, too few values",
+			place,
+			`{"name": "home", "location": [51.5]}`,
+			`array length out of range, in location: "1", expected 2`,
+		},
+		{
+			"Invalid location, too many values",
+			place,
+			`{"name": "home", "location": [51.5, -0.12, 10]}`,
+			`array length out of range, in location: "3", expected 2`,
+		},
+		{
+			"Invalid location, value out of range",
+			place,
+			`{"name": "home", "location": [51.5, 200]}`,
+			`value out of range: "200"`,
+		},
+		{
+			"Valid reading",
+			reading,
+			`{"sensor": "a", "reading": ["2024-01-02T03:04:05Z", 21.5, "C"]}`,
+			"",
+		},
+		{
+			"Valid reading with labels",
+			reading,
+			`{"sensor": "a", "reading": ["2024-01-02T03:04:05Z", 21.5, "C", "indoor", "east"]}`,
+			"",
+		},
+		{
+			"Invalid reading, value in the wrong position",
+			reading,
+			`{"sensor": "a", "reading": [21.5, "2024-01-02T03:04:05Z", "C"]}`,
+			`invalid data: "21.5"`,
+		},
+		{
+			"Invalid reading, invalid unit",
+			reading,
+			`{"sensor": "a", "reading": ["2024-01-02T03:04:05Z", 21.5, "K"]}`,
+			`invalid enumerated value: "K", expected one of C, F`,
+		},
+		{
+			"Invalid reading, too few values",
+			reading,
+			`{"sensor": "a", "reading": ["2024-01-02T03:04:05Z", 21.5]}`,
+			`array length out of range, in reading: "2", expected 3`,
+		},
+		{
+			"Invalid reading, invalid label",
+			reading,
+			`{"sensor": "a", "reading": ["2024-01-02T03:04:05Z", 21.5, "C", ""]}`,
+			`value length out of range`,
+		},
+		{
+			"Invalid reading, not an array",
+			reading,
+			`{"sensor": "a", "reading": {"time": 1}}`,
+			`invalid data, in reading: "map[time:1]"`,
+		},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := test.item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for _, item := range []*validator.Item{place, reading} {
+		for seed := range int64(50) {
+			g := validator.NewGenerator(seed)
+
+			docs, err := g.Invalid(item)
+			if err != nil {
+				t.Fatalf("Invalid() unexpected error: %v", err)
+			}
+
+			for _, doc := range docs {
+				var msg string
+
+				if err := item.Validate(doc.Text); err != nil {
+					msg = err.Error()
+				}
+
+				if msg != doc.Err.Error() {
+					t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+						doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+				}
+			}
+
+			validator.CheckProperties(t, item, "", seed)
+		}
+	}
+}
+
+func Test_TupleDefinitions(t *testing.T) {
+	item, err := validator.Compile(readingDefinition)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    sensor string: required\n    reading (time; float: minvalue=0; string: enum=(C,F)): required, additional=(string: minlen=1)\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if copied.Source() != source {
+		t.Fatalf("NewJSON() unexpected source:\n%s", copied.Source())
+	}
+
+	if text := copied.Describe(); text != "object\n    sensor: required string\n    reading: required tuple of time, number, string, followed by string\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// Positions without attributes can be separated by commas.
+	point, err := validator.Compile(`(float, float)`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	if source := point.Source(); source != "(float, float)\n" {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	if err := point.Validate(`[1, 2.5]`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := point.Validate(`[1, true]`); err == nil || err.Error() != `invalid data: "true"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	// Setting the rules directly.
+	api := validator.NewTuple(validator.NewType(validator.TypeString), validator.NewType(validator.TypeInt)).
+		SetAdditionalItems(validator.NewType(validator.TypeBool))
+
+	if err := api.Validate(`["a", 1, true, false]`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := api.Validate(`["a", 1, 2]`); err == nil || err.Error() != `invalid data: "2"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "tuple"}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for a tuple without items")
+	}
+
+	if _, err := validator.Compile(`(int: minvalue=1, string)`); err == nil {
+		t.Fatal("Compile() expected an error for attributes followed by a comma")
+	}
+}
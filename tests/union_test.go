@@ -0,0 +1,382 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tucats/validator"
+)
+
+// Shape is an interface with registered implementations, so a field with this
+// type is validated as a union of the implementations.
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 `json:"radius" validate:"required,minvalue=0"`
+}
+
+func (c Circle) Area() float64 { return 3.14159 * c.Radius * c.Radius }
+
+type Square struct {
+	Side float64 `json:"side" validate:"required,minvalue=0"`
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// Drawing has a field that is one of the shapes.
+type Drawing struct {
+	Name  string `json:"name"  validate:"required"`
+	Shape Shape  `json:"shape" validate:"required"`
+}
+
+// The event envelope has a payload selected by its kind property.
+const envelopeDefinition = `{
+	id string: required
+	payload union(created { user string: required }; deleted { user string: required; reason string: enum=spam|request }): discriminator=kind, required
+}`
+
+func Test_Union(t *testing.T) {
+	if err := validator.RegisterUnion(reflect.TypeOf((*Shape)(nil)).Elem(), Circle{}, &Square{}); err != nil {
+		t.Fatalf("RegisterUnion() unexpected error: %v", err)
+	}
+
+	drawing, err := validator.New(&Drawing{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	envelope, err := validator.Compile(envelopeDefinition)
+	if err != nil {
+		t.Fatal("Failed to compile definition:", err)
+	}
+
+	tests := []struct {
+		name     string
+		item     *validator.Item
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid circle",
+			drawing,
+			`{"name": "a", "shape": {"radius": 2}}`,
+			"",
+		},
+		{
+			"Valid square",
+			drawing,
+			`{"name": "a", "shape": {"side": 2}}`,
+			"",
+		},
+		{
+			"Invalid shape, no variant matches",
+			drawing,
+			`{"name": "a", "shape": {"radius": -1}}`,
+			`value does not match any variant, in shape`,
+		},
+		{
+			"Invalid shape, not an object",
+			drawing,
+			`{"name": "a", "shape": 5}`,
+			`value does not match any variant, in shape`,
+		},
+		{
+			"Valid created event",
+			envelope,
+			`{"id": "1", "payload": {"kind": "created", "user": "tom"}}`,
+			"",
+		},
+		{
+			"Valid deleted event",
+			envelope,
+			`{"id": "1", "payload": {"kind": "deleted", "user": "tom", "reason": "spam"}}`,
+			"",
+		},
+		{
+			"Invalid deleted event, error from the selected variant",
+			envelope,
+			`{"id": "1", "payload": {"kind": "deleted", "user": "tom", "reason": "bored"}}`,
+			`invalid enumerated value, in reason: "bored", expected one of spam, request`,
+		},
+		{
+			"Invalid event, missing field of the selected variant",
+			envelope,
+			`{"id": "1", "payload": {"kind": "created"}}`,
+			`required field missing: "user"`,
+		},
+		{
+			"Invalid event, unknown kind",
+			envelope,
+			`{"id": "1", "payload": {"kind": "renamed", "user": "tom"}}`,
+			`invalid discriminator value, in payload: "renamed", expected one of created, deleted`,
+		},
+		{
+			"Invalid event, missing kind",
+			envelope,
+			`{"id": "1", "payload": {"user": "tom"}}`,
+			`required field missing, in payload: "kind"`,
+		},
+		{
+			"Invalid event, payload is not an object",
+			envelope,
+			`{"id": "1", "payload": "created"}`,
+			`invalid data, in payload: "created"`,
+		},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := test.item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for _, item := range []*validator.Item{drawing, envelope} {
+		for seed := range int64(50) {
+			g := validator.NewGenerator(seed)
+
+			docs, err := g.Invalid(item)
+			if err != nil {
+				t.Fatalf("Invalid() unexpected error: %v", err)
+			}
+
+			for _, doc := range docs {
+				var msg string
+
+				if err := item.Validate(doc.Text); err != nil {
+					msg = err.Error()
+				}
+
+				if msg != doc.Err.Error() {
+					t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+						doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+				}
+			}
+
+			validator.CheckProperties(t, item, "", seed)
+		}
+	}
+}
+
+func Test_UnionDefinitions(t *testing.T) {
+	item, err := validator.Compile(envelopeDefinition)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	source := item.Source()
+	if expected := "{\n    id string: required\n    payload union(created {\n        user string: required\n    }; deleted {\n" +
+		"        user string: required\n        reason string: enum=(spam,request)\n    }): required, discriminator=kind\n}\n"; source != expected {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	copied, err := validator.NewJSON([]byte(item.String()))
+	if err != nil {
+		t.Fatalf("NewJSON() unexpected error: %v", err)
+	}
+
+	if copied.Source() != source {
+		t.Fatalf("NewJSON() unexpected source:\n%s", copied.Source())
+	}
+
+	if text := copied.Describe(); text != "object\n    id: required string\n    payload: required one of created object, deleted object, selected by kind\n" {
+		t.Fatalf("Describe() unexpected result:\n%s", text)
+	}
+
+	// A union without a discriminator, where a value can match more than one
+	// variant unless the mode is anyof.
+	numbers, err := validator.Compile(`union(int: maxvalue=10; int: minvalue=5; bool)`)
+	if err != nil {
+		t.Fatalf("Compile() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		jsonText string
+		expected string
+	}{
+		{`3`, ""},
+		{`true`, ""},
+		{`7`, `value matches more than one variant: "2"`},
+		{`"text"`, `value does not match any variant`},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := numbers.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("Validate(%s) unexpected result: %s", test.jsonText, msg)
+		}
+	}
+
+	if err := numbers.SetUnionMode(validator.UnionAnyOf).Validate(`7`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if source := numbers.Source(); source != "union(int: maxvalue=10; int: minvalue=5; bool): mode=anyof\n" {
+		t.Fatalf("Source() unexpected result:\n%s", source)
+	}
+
+	// Setting the rules directly.
+	api := validator.NewType(validator.TypeUnion).
+		AddVariant("on", validator.NewType(validator.TypeStruct)).
+		AddVariant("off", validator.NewType(validator.TypeStruct).AddField(*validator.NewType(validator.TypeInt).SetName("code"))).
+		SetDiscriminator("state")
+
+	if err := api.Validate(`{"state": "off", "code": 3}`); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	if err := api.Validate(`{"state": "on", "code": 3}`); err == nil || err.Error() != `invalid field name: "code"` {
+		t.Fatalf("Validate() unexpected result: %v", err)
+	}
+
+	if err := validator.NewType(validator.TypeInt).ParseTag("discriminator=kind"); err == nil ||
+		err.Error() != `keyword only valid with union type, in discriminator: "int"` {
+		t.Fatalf("ParseTag() unexpected result: %v", err)
+	}
+
+	if _, err := validator.NewJSON([]byte(`{"type": "union"}`)); err == nil {
+		t.Fatal("NewJSON() expected an error for a union without variants")
+	}
+
+	if _, err := validator.Compile(`union(a int; a string): discriminator=kind`); err == nil {
+		t.Fatal("Compile() expected an error for duplicate discriminator values")
+	}
+
+	if err := validator.RegisterUnion(reflect.TypeOf((*Shape)(nil)).Elem(), 5); err == nil {
+		t.Fatal("RegisterUnion() expected an error for a type that does not implement the interface")
+	}
+}
+
+// Figure is an interface whose implementations are selected by the "type"
+// property of the JSON object.
+type Figure interface {
+	Corners() int
+}
+
+func (c Circle) Corners() int { return 0 }
+
+func (s Square) Corners() int { return 4 }
+
+// Triangle has a field for the discriminator property.
+type Triangle struct {
+	Type string  `json:"type" validate:"required"`
+	Base float64 `json:"base" validate:"required,minvalue=0"`
+}
+
+func (t *Triangle) Corners() int { return 3 }
+
+// Canvas has fields with the interface type.
+type Canvas struct {
+	Main   Figure   `json:"main"   validate:"required"`
+	Others []Figure `json:"others"`
+}
+
+func Test_Implementations(t *testing.T) {
+	err := validator.RegisterImplementations[Figure]("type", map[string]any{
+		"circle":   Circle{},
+		"square":   Square{},
+		"triangle": &Triangle{},
+	})
+	if err != nil {
+		t.Fatalf("RegisterImplementations() unexpected error: %v", err)
+	}
+
+	item, err := validator.New(&Canvas{})
+	if err != nil {
+		t.Fatal("Failed to define structure:", err)
+	}
+
+	tests := []struct {
+		name     string
+		jsonText string
+		expected string
+	}{
+		{
+			"Valid figures",
+			`{"main": {"type": "circle", "radius": 1}, "others": [{"type": "square", "side": 2}, {"type": "triangle", "base": 3}]}`,
+			"",
+		},
+		{
+			"Invalid circle",
+			`{"main": {"type": "circle", "radius": -1}}`,
+			`value out of range, in radius: "-1"`,
+		},
+		{
+			"Invalid circle, field of another figure",
+			`{"main": {"type": "circle", "side": 2}}`,
+			`invalid field name: "side"`,
+		},
+		{
+			"Invalid triangle in array",
+			`{"main": {"type": "square", "side": 2}, "others": [{"type": "triangle"}]}`,
+			`required field missing: "base"`,
+		},
+		{
+			"Invalid figure type",
+			`{"main": {"type": "hexagon", "side": 2}}`,
+			`invalid discriminator value, in main: "hexagon", expected one of circle, square, triangle`,
+		},
+		{
+			"Invalid figure, missing type",
+			`{"main": {"side": 2}}`,
+			`required field missing, in main: "type"`,
+		},
+	}
+
+	for _, test := range tests {
+		msg := ""
+		if err := item.Validate(test.jsonText); err != nil {
+			msg = err.Error()
+		}
+
+		if msg != test.expected {
+			t.Fatalf("In \"%s\", unexpected result: %s\n", test.name, msg)
+		}
+	}
+
+	for seed := range int64(50) {
+		g := validator.NewGenerator(seed)
+
+		docs, err := g.Invalid(item)
+		if err != nil {
+			t.Fatalf("Invalid() unexpected error: %v", err)
+		}
+
+		for _, doc := range docs {
+			var msg string
+
+			if err := item.Validate(doc.Text); err != nil {
+				msg = err.Error()
+			}
+
+			if msg != doc.Err.Error() {
+				t.Fatalf("Invalid() document for %s at %q with seed %d\n  wanted: %v\n  got:    %s\n%s",
+					doc.Rule, doc.Path, seed, doc.Err, msg, doc.Text)
+			}
+		}
+
+		validator.CheckProperties(t, item, "", seed)
+	}
+
+	if err := validator.RegisterImplementations[Figure]("type", map[string]any{"text": "text"}); err == nil {
+		t.Fatal("RegisterImplementations() expected an error for a type that does not implement the interface")
+	}
+
+	if err := validator.RegisterImplementations[Circle]("type", map[string]any{"circle": Circle{}}); err == nil {
+		t.Fatal("RegisterImplementations() expected an error for a type that is not an interface")
+	}
+
+	if err := validator.RegisterImplementations[Figure]("", map[string]any{"circle": Circle{}}); err == nil {
+		t.Fatal("RegisterImplementations() expected an error for a missing property")
+	}
+}
@@ -0,0 +1,216 @@
+package validator
+
+import (
+	"strings"
+)
+
+// NewTuple creates a tuple validator, which accepts a JSON array with one value
+// for each of the given validators, in the same order. By default, the array
+// cannot have any other values.
+func NewTuple(items ...*Item) *Item {
+	item := NewType(TypeTuple)
+	item.Items = items
+
+	return item
+}
+
+// SetAdditionalItems sets the validator for the values of a tuple that follow
+// the values for each position. If this is not set, the array cannot have more
+// values than positions. If the item is not a tuple, no change is made to the
+// item.
+func (i *Item) SetAdditionalItems(v *Item) *Item {
+	if i == nil || i.ItemType != TypeTuple {
+		return i
+	}
+
+	i.AdditionalItems = v
+
+	return i
+}
+
+// validateTuple checks the length of an array against the positions of a tuple,
+// and then checks each value using the validator for its position. The values
+// after the positions are checked using the additional items validator.
+func (i *Item) validateTuple(v any, depth int) error {
+	array, ok := v.([]any)
+	if !ok {
+		return ErrInvalidData.Context(i.Name).Value(v)
+	}
+
+	if len(array) < len(i.Items) || len(array) > len(i.Items) && i.AdditionalItems == nil {
+		return ErrArrayLengthOutOfRange.Context(i.Name).Value(len(array)).Expected(len(i.Items))
+	}
+
+	if i.HasMaxLength && len(array) > i.MaxLength {
+		return ErrArrayLengthOutOfRange.Context(i.Name).Value(len(array)).Expected(i.MaxLength)
+	}
+
+	for n, element := range array {
+		item := i.AdditionalItems
+		if n < len(i.Items) {
+			item = i.Items[n]
+		}
+
+		if err := item.validateValue(element, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyTuple reports an error if a tuple does not have any positions, or if
+// another type has positions or a validator for additional items.
+func (i *Item) verifyTuple() error {
+	if i.ItemType != TypeTuple {
+		if len(i.Items) > 0 || i.AdditionalItems != nil {
+			return ErrInvalidValidator.Context("items").Value(i.ItemType.String())
+		}
+
+		return nil
+	}
+
+	if len(i.Items) == 0 {
+		return ErrInvalidValidator.Context("items").Value("missing items")
+	}
+
+	return nil
+}
+
+// writeTuple writes the positions of a tuple as validator source, as in
+// "(float, float)". If any position has attributes, the positions are
+// separated by semicolons, because the attributes are separated by commas.
+func (i *Item) writeTuple(b *strings.Builder, depth int) {
+	separator := ", "
+
+	for _, item := range i.Items {
+		if item != nil && len(item.attributes()) > 0 {
+			separator = "; "
+		}
+	}
+
+	b.WriteString("(")
+
+	for n, item := range i.Items {
+		if n > 0 {
+			b.WriteString(separator)
+		}
+
+		if item == nil {
+			b.WriteString(typeWord(TypeAny))
+		} else {
+			item.writeDefinition(b, depth)
+		}
+	}
+
+	// A single map in parentheses groups the map rather than being a tuple,
+	// so a tuple with only a map position ends with a separator.
+	if len(i.Items) == 1 && i.Items[0] != nil && i.Items[0].ItemType == TypeMap {
+		b.WriteString(",")
+	}
+
+	b.WriteString(")")
+}
+
+// compileTuple compiles the list of positions of a tuple, after the opening
+// parenthesis. The positions are separated by commas or semicolons, but the
+// attributes of a position end only at a semicolon or the closing parenthesis.
+// A separator can follow the last position.
+//
+// A single map declaration in parentheses is not a tuple. It groups the map with
+// the rules for its values, so the attributes that follow the parentheses belong
+// to an enclosing array or pointer, as in "[](map[string] int: minvalue=1): minlen=1".
+func compileTuple(t *tokenizer, item *Item) error {
+	item.ItemType = TypeTuple
+
+	for {
+		position := &Item{}
+		if err := compileDefinition(t, position, position); err != nil {
+			return err
+		}
+
+		item.Items = append(item.Items, position)
+
+		switch next := t.next(); next {
+		case ",", ";":
+			if t.peek(0) == ")" {
+				t.next()
+
+				return nil
+			}
+
+		case ")":
+			if len(item.Items) == 1 && position.ItemType == TypeMap {
+				name := item.Name
+				*item = *position
+				item.Name = name
+			}
+
+			return nil
+
+		default:
+			return ErrSyntaxError.Context(t.pos()).Value(next).Expected(",", ";", ")")
+		}
+	}
+}
+
+// describeTuple describes the positions of a tuple, such as "tuple of number,
+// number, followed by string".
+func (i *Item) describeTuple() string {
+	list := make([]string, len(i.Items))
+	for n, item := range i.Items {
+		list[n] = item.elementPhrase()
+	}
+
+	text := "tuple of " + strings.Join(list, ", ")
+
+	if i.AdditionalItems != nil {
+		text += ", followed by " + i.AdditionalItems.elementPhrase()
+	}
+
+	return text
+}
+
+// tupleValue creates a valid array for a tuple, with a value for each position,
+// and up to two additional values if the tuple allows them. The mutations are
+// the mutations of the values, and arrays with too few or too many values.
+func (g *Generator) tupleValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	extra := 0
+	if i.AdditionalItems != nil && depth < g.MaxDepth {
+		extra = g.rand.Intn(3)
+	}
+
+	if i.HasMaxLength {
+		extra = max(min(extra, i.MaxLength-len(i.Items)), 0)
+	}
+
+	array := make([]any, len(i.Items)+extra)
+
+	for n := range array {
+		item := i.AdditionalItems
+		if n < len(i.Items) {
+			item = i.Items[n]
+		}
+
+		element, err := g.value(item, appendPath(path, n), depth+1, collect && n <= len(i.Items))
+		if err != nil {
+			return nil, err
+		}
+
+		array[n] = element
+	}
+
+	if collect {
+		g.mutate(path, "text", "type", ErrInvalidData.Context(i.Name).Value("text"))
+
+		short := copyValue(array[:len(i.Items)-1])
+		g.mutate(path, short, "items", ErrArrayLengthOutOfRange.Context(i.Name).Value(len(i.Items)-1).Expected(len(i.Items)))
+
+		if i.AdditionalItems == nil {
+			long := append(copyValue(array).([]any), copyValue(array[0]))
+			g.mutate(path, long, "items", ErrArrayLengthOutOfRange.Context(i.Name).Value(len(long)).Expected(len(i.Items)))
+		}
+	}
+
+	return array, nil
+}
@@ -28,6 +28,9 @@ const (
 	TypeAny
 	TypeList
 	TypeDuration
+	TypeCustom
+	TypeUnion
+	TypeTuple
 )
 
 // Map used to convert Type values to a string name.
@@ -46,6 +49,9 @@ var TypeNames = map[Type]string{
 	TypeDuration: "time.Duration",
 	TypeMap:      "map[string]any",
 	TypeList:     "stringList",
+	TypeCustom:   "custom",
+	TypeUnion:    "union",
+	TypeTuple:    "tuple",
 }
 
 var TypeNamesMap = map[string]Type{
@@ -58,6 +64,9 @@ var TypeNamesMap = map[string]Type{
 	"duration": TypeDuration,
 	"map":      TypeMap,
 	"list":     TypeList,
+	"any":      TypeAny,
+	"custom":   TypeCustom,
+	"union":    TypeUnion,
 }
 
 // String method for Type to return the string name of the type. Mostly
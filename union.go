@@ -0,0 +1,496 @@
+package validator
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The modes for a union, which decide how many of the variants a value must
+// match when the union does not have a discriminator.
+const (
+	// The value must match exactly one of the variants. This is the default.
+	UnionOneOf = GroupOneOf
+
+	// The value must match at least one of the variants.
+	UnionAnyOf = GroupAnyOf
+)
+
+// Variant is one of the validators of a union. If the union has a discriminator,
+// the variant is selected when the discriminator property of the JSON object has
+// the Match value.
+type Variant struct {
+	// The value of the discriminator property that selects this variant. If
+	// the union does not have a discriminator, this is an empty string.
+	Match string `json:"match,omitempty"`
+
+	// The validator for a value of this variant.
+	Value *Item `json:"value"`
+}
+
+// The unions used for Go interface types, stored by the interface type. Access
+// to the map is serialized by a mutex.
+var unions = map[reflect.Type]*Item{}
+
+var unionsLock sync.Mutex
+
+// NewUnion creates a union validator that accepts a value that matches exactly
+// one of the given validators.
+func NewUnion(variants ...*Item) *Item {
+	item := NewType(TypeUnion)
+
+	for _, variant := range variants {
+		item.AddVariant("", variant)
+	}
+
+	return item
+}
+
+// AddVariant adds a validator to a union. The match value is the value of the
+// discriminator property that selects the variant, and is ignored if the union
+// does not have a discriminator. If the item is not a union, no change is made
+// to the item.
+func (i *Item) AddVariant(match string, v *Item) *Item {
+	if i == nil || i.ItemType != TypeUnion {
+		return i
+	}
+
+	i.Variants = append(i.Variants, Variant{Match: match, Value: v})
+
+	return i
+}
+
+// SetDiscriminator sets the name of the property of a JSON object that selects
+// the variant of a union used to validate the object. The other variants are not
+// tried, so the errors for the value are reported by the selected variant. If the
+// item is not a union, no change is made to the item.
+func (i *Item) SetDiscriminator(property string) *Item {
+	if i == nil || i.ItemType != TypeUnion {
+		return i
+	}
+
+	i.Discriminator = property
+
+	return i
+}
+
+// SetUnionMode sets how many variants of a union a value must match, which is
+// either UnionOneOf or UnionAnyOf. If the item is not a union, no change is made
+// to the item.
+func (i *Item) SetUnionMode(mode string) *Item {
+	if i == nil || i.ItemType != TypeUnion {
+		return i
+	}
+
+	i.UnionMode = mode
+
+	return i
+}
+
+// RegisterUnion registers the Go types that implement an interface type. When
+// New() finds a field with the interface type, it creates a union validator that
+// accepts a value that matches exactly one of the types. Each value must be a
+// value of a type that implements the interface, or a pointer to one.
+func RegisterUnion(t reflect.Type, values ...any) error {
+	if t == nil || t.Kind() != reflect.Interface || len(values) == 0 {
+		return ErrUnsupportedType.Value(t)
+	}
+
+	item := NewType(TypeUnion)
+
+	for _, value := range values {
+		v, err := implementation(t, value)
+		if err != nil {
+			return err
+		}
+
+		item.AddVariant("", v)
+	}
+
+	storeUnion(t, item)
+
+	return nil
+}
+
+// RegisterImplementations registers the Go types that implement the interface
+// type T, each selected by a value of the discriminator property of the JSON
+// object. When New() finds a field with the interface type, it creates a union
+// validator that uses the property to select the type that checks the object.
+// Each value in the map must be a value of a type that implements the interface,
+// or a pointer to one.
+func RegisterImplementations[T any](property string, types map[string]any) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Interface || len(types) == 0 {
+		return ErrUnsupportedType.Value(t)
+	}
+
+	if property == "" {
+		return ErrInvalidName.Value(property)
+	}
+
+	item := NewType(TypeUnion).SetDiscriminator(property)
+
+	// Sort the discriminator values so the validator is always the same.
+	matches := make([]string, 0, len(types))
+	for match := range types {
+		matches = append(matches, match)
+	}
+
+	sort.Strings(matches)
+
+	for _, match := range matches {
+		v, err := implementation(t, types[match])
+		if err != nil {
+			return err
+		}
+
+		item.AddVariant(match, v)
+	}
+
+	if err := item.verifyUnion(); err != nil {
+		return err
+	}
+
+	storeUnion(t, item)
+
+	return nil
+}
+
+// implementation returns the validator for a Go type that implements the
+// interface type.
+func implementation(t reflect.Type, value any) (*Item, error) {
+	vt := reflect.TypeOf(value)
+	if vt == nil || !(vt.Implements(t) || reflect.PointerTo(vt).Implements(t)) {
+		return nil, ErrUnsupportedType.Context(t.String()).Value(value)
+	}
+
+	return New(value)
+}
+
+// storeUnion stores the union validator used for an interface type.
+func storeUnion(t reflect.Type, item *Item) {
+	unionsLock.Lock()
+	defer unionsLock.Unlock()
+
+	unions[t] = item
+}
+
+// findUnion returns a copy of the union validator used for an interface type.
+func findUnion(t reflect.Type) (*Item, bool) {
+	unionsLock.Lock()
+	defer unionsLock.Unlock()
+
+	item, found := unions[t]
+
+	return item.Copy(), found
+}
+
+// validateUnion checks a value against the variants of a union. If the union has
+// a discriminator, the variant is selected by the discriminator property of the
+// object. Otherwise, the value must match exactly one of the variants, or at least
+// one of them if the union mode is UnionAnyOf.
+func (i *Item) validateUnion(v any, depth int) error {
+	if i.Discriminator != "" {
+		variant, value, err := i.selectVariant(v)
+		if err != nil {
+			return err
+		}
+
+		return variant.validateValue(value, depth+1)
+	}
+
+	matches := 0
+
+	for _, variant := range i.Variants {
+		if variant.Value.validateValue(v, depth+1) != nil {
+			continue
+		}
+
+		if i.UnionMode == UnionAnyOf {
+			return nil
+		}
+
+		matches++
+	}
+
+	switch matches {
+	case 0:
+		return ErrNoVariant.Context(i.Name)
+
+	case 1:
+		return nil
+
+	default:
+		return ErrManyVariants.Context(i.Name).Value(matches)
+	}
+}
+
+// selectVariant returns the variant of a union selected by the discriminator
+// property of a JSON object, and the value to check against it. If the variant
+// is a structure that does not have a field for the discriminator, the property
+// is removed from the value.
+func (i *Item) selectVariant(v any) (*Item, any, error) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, nil, ErrInvalidData.Context(i.Name).Value(v)
+	}
+
+	value, found := m[i.Discriminator]
+	if !found {
+		return nil, nil, ErrRequired.Context(i.Name).Value(i.Discriminator)
+	}
+
+	text, _ := value.(string)
+
+	for _, variant := range i.Variants {
+		if variant.Match != text || variant.Value == nil {
+			continue
+		}
+
+		s := variant.Value.resolve()
+		if s == nil || s.ItemType != TypeStruct || s.hasField(i.Discriminator) {
+			return variant.Value, v, nil
+		}
+
+		rest := make(map[string]any, len(m))
+		for key, element := range m {
+			if key != i.Discriminator {
+				rest[key] = element
+			}
+		}
+
+		return variant.Value, rest, nil
+	}
+
+	return nil, nil, ErrInvalidDiscriminator.Context(i.Name).Value(value).Expected(i.matches())
+}
+
+// matches returns the sorted list of the discriminator values of a union.
+func (i *Item) matches() []string {
+	list := make([]string, 0, len(i.Variants))
+	for _, variant := range i.Variants {
+		list = append(list, variant.Match)
+	}
+
+	sort.Strings(list)
+
+	return list
+}
+
+// verifyUnion reports an error if a union does not have any variants, if the
+// union mode is not known, or if the discriminator values are missing or used
+// by more than one variant.
+func (i *Item) verifyUnion() error {
+	if i.ItemType != TypeUnion {
+		if len(i.Variants) > 0 || i.Discriminator != "" || i.UnionMode != "" {
+			return ErrInvalidValidator.Context("variants").Value(i.ItemType.String())
+		}
+
+		return nil
+	}
+
+	if len(i.Variants) == 0 {
+		return ErrInvalidValidator.Context("variants").Value("missing variants")
+	}
+
+	if i.UnionMode != "" && i.UnionMode != UnionOneOf && i.UnionMode != UnionAnyOf {
+		return ErrInvalidValidator.Context("union_mode").Value(i.UnionMode)
+	}
+
+	if i.Discriminator == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+
+	for _, variant := range i.Variants {
+		if variant.Match == "" || seen[variant.Match] {
+			return ErrInvalidValidator.Context("match").Value(variant.Match)
+		}
+
+		seen[variant.Match] = true
+	}
+
+	return nil
+}
+
+// writeUnion writes the variants of a union as validator source, as in
+// "union(circle {...}; square {...})". The discriminator value of each
+// variant is written as its name.
+func (i *Item) writeUnion(b *strings.Builder, depth int) {
+	b.WriteString(typeWord(TypeUnion))
+	b.WriteString("(")
+
+	for n, variant := range i.Variants {
+		if n > 0 {
+			b.WriteString("; ")
+		}
+
+		if variant.Match != "" {
+			b.WriteString(quoteName(variant.Match))
+			b.WriteString(" ")
+		}
+
+		if variant.Value == nil {
+			b.WriteString(typeWord(TypeAny))
+		} else {
+			variant.Value.writeDefinition(b, depth)
+		}
+	}
+
+	b.WriteString(")")
+}
+
+// compileUnion compiles the list of variants of a union, which follows the
+// "union" type word. Each variant is written like a field, and the name of the
+// variant is the value of the discriminator property that selects it.
+func compileUnion(t *tokenizer, item *Item) error {
+	if next := t.next(); next != "(" {
+		return ErrSyntaxError.Context(t.pos()).Value(next).Expected("(")
+	}
+
+	for {
+		switch t.peek(0) {
+		case ";":
+			t.next()
+
+			continue
+
+		case ")":
+			t.next()
+
+			if len(item.Variants) == 0 {
+				return ErrSyntaxError.Context(t.pos()).Expected("type")
+			}
+
+			return nil
+
+		case "":
+			return ErrSyntaxError.Context(t.pos()).Expected(")")
+		}
+
+		variant, err := compileItem(t)
+		if err != nil {
+			return err
+		}
+
+		item.Variants = append(item.Variants, Variant{Match: variant.Name, Value: variant})
+		variant.Name = ""
+
+		if next := t.peek(0); next != ";" && next != ")" {
+			t.next()
+
+			return ErrSyntaxError.Context(t.pos()).Value(next).Expected(";", ")")
+		}
+	}
+}
+
+// describeUnion describes the variants of a union, such as "one of circle
+// object, square object, selected by kind".
+func (i *Item) describeUnion() string {
+	text := "one of "
+	if i.UnionMode == UnionAnyOf {
+		text = "any of "
+	}
+
+	list := make([]string, len(i.Variants))
+	for n, variant := range i.Variants {
+		list[n] = strings.TrimSpace(variant.Match + " " + variant.Value.typePhrase())
+	}
+
+	text += strings.Join(list, ", ")
+
+	if i.Discriminator != "" {
+		text += ", selected by " + i.Discriminator
+	}
+
+	return text
+}
+
+// unionValue creates a valid value for a union, using a randomly chosen variant.
+// If the union has a discriminator, the discriminator property is set, and the
+// mutations of the variant are used if they cause the same error for the union.
+// Otherwise, a value that does not match any variant is used as a mutation.
+func (g *Generator) unionValue(i *Item, path []any, depth int, collect bool) (any, error) {
+	first := len(g.mutations)
+
+	for range generatorAttempts {
+		g.mutations = g.mutations[:first]
+
+		variant := i.Variants[g.rand.Intn(len(i.Variants))]
+		if variant.Value == nil {
+			continue
+		}
+
+		value, err := g.value(variant.Value, path, depth+1, collect && i.Discriminator != "")
+		if err != nil {
+			return nil, err
+		}
+
+		if m, ok := value.(map[string]any); ok && i.Discriminator != "" {
+			m[i.Discriminator] = variant.Match
+		}
+
+		doc, err := jsonObject(map[string]any{"value": value})
+		if err != nil || i.validateValue(doc["value"], depth) != nil {
+			continue
+		}
+
+		if collect {
+			g.checkMutations(i, path, depth, first, value)
+			g.unionMutations(i, path, depth)
+		}
+
+		return value, nil
+	}
+
+	return nil, ErrCannotGenerate.Context(i.Name).Value("no values match exactly one variant")
+}
+
+// unionMutations adds the mutations for a union. With a discriminator, these are
+// a value that is not an object, an object without the discriminator property,
+// and a discriminator value that does not select a variant. Without one, the
+// first of a few values of different types that the union does not accept is used.
+func (g *Generator) unionMutations(i *Item, path []any, depth int) {
+	if i.Discriminator == "" {
+		for _, probe := range []any{"text", 1.5, true, []any{}, map[string]any{}} {
+			if err := i.validateValue(probe, depth); err != nil {
+				g.mutate(path, probe, "union", err)
+
+				break
+			}
+		}
+
+		return
+	}
+
+	g.mutate(path, "text", "type", ErrInvalidData.Context(i.Name).Value("text"))
+
+	g.mutations = append(g.mutations, mutation{
+		path:   appendPath(path, i.Discriminator),
+		remove: true,
+		rule:   "discriminator",
+		err:    ErrRequired.Context(i.Name).Value(i.Discriminator),
+	})
+
+	word := "unknown"
+	for n := 1; i.hasMatch(word); n++ {
+		word = "unknown" + strconv.Itoa(n)
+	}
+
+	g.mutate(appendPath(path, i.Discriminator), word, "discriminator",
+		ErrInvalidDiscriminator.Context(i.Name).Value(word).Expected(i.matches()))
+}
+
+// hasMatch reports if a discriminator value selects a variant of the union.
+func (i *Item) hasMatch(value string) bool {
+	for _, variant := range i.Variants {
+		if variant.Match == value {
+			return true
+		}
+	}
+
+	return false
+}
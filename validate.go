@@ -2,7 +2,7 @@ package validator
 
 import (
 	"encoding/json"
-	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -31,8 +31,9 @@ func (i *Item) Validate(text string) error {
 		v   any
 	)
 
-	// Parse the JSON into an abstract object.
-	err = json.Unmarshal([]byte(text), &v)
+	// Parse the JSON into an abstract object. Numbers are kept as their
+	// text, so integers and decimal values can be checked exactly.
+	err = decodeJSON([]byte(text), &v)
 	if err != nil {
 		return err
 	}
@@ -59,6 +60,33 @@ func (i *Item) validateValue(v any, depth int) error {
 		}
 	}
 
+	// A custom type decides for itself if a null value is valid, the same
+	// way encoding/json lets the type's own methods handle null.
+	if i.ItemType == TypeCustom {
+		return i.validateCustom(v)
+	}
+
+	// A null value is only valid if the item is nullable. This is checked
+	// before any other rules, since no other rule can accept a null value.
+	if v == nil && i.ItemType != TypeAny {
+		if i.Nullable {
+			return nil
+		}
+
+		return ErrNullNotAllowed.Context(i.Name)
+	}
+
+	// If the value is encoded inside a string, extract it before applying
+	// the rules for this item.
+	if i.Quoted {
+		value, err := i.quotedValue(v)
+		if err != nil {
+			return err
+		}
+
+		v = value
+	}
+
 	// Based on the item's type, perform the appropriate validations.
 	switch i.ItemType {
 	case TypeAny:
@@ -88,46 +116,46 @@ func (i *Item) validateValue(v any, depth int) error {
 			}
 		}
 
-		return nil
+		return i.validateElements(array, depth)
+
+	case TypeTuple:
+		return i.validateTuple(v, depth)
 
 	case TypeMap:
-		// A current limitation of maps is that the key must always be of type string.
-		if len(i.Enums) > 0 {
-			actual := reflect.ValueOf(v)
-			keys := actual.MapKeys()
+		actual, ok := v.(map[string]any)
+		if !ok {
+			return ErrInvalidData.Context(i.Name).Value(v)
+		}
 
-			for _, key := range keys {
-				// Validate that the key value itself is valid.
-				found := false
-				keyString := key.String()
+		if i.HasMinLength && len(actual) < i.MinLength {
+			return ErrMapLengthOutOfRange.Context(i.Name).Value(len(actual)).Expected(i.MinLength)
+		}
 
-				for _, enum := range i.Enums {
-					if i.CaseSensitive {
-						if keyString == enum {
-							found = true
+		if i.HasMaxLength && len(actual) > i.MaxLength {
+			return ErrMapLengthOutOfRange.Context(i.Name).Value(len(actual)).Expected(i.MaxLength)
+		}
 
-							break
-						}
-					} else {
-						if strings.EqualFold(keyString, enum) {
-							found = true
+		// Check the keys in sorted order, so the error reported for a map
+		// with more than one invalid key or value is always the same.
+		keys := make([]string, 0, len(actual))
+		for key := range actual {
+			keys = append(keys, key)
+		}
 
-							break
-						}
-					}
-				}
+		sort.Strings(keys)
 
-				if !found {
-					return ErrInvalidEnumeratedValue.Context(i.Name).Value(keyString).Expected(i.Enums)
-				}
+		for _, key := range keys {
+			if err := i.validateKey(key, depth); err != nil {
+				return err
+			}
 
-				// Validate that the value of the key in this map is also valid.
-				mapValue := actual.MapIndex(key).Interface()
+			// A map without a value type accepts any values.
+			if i.BaseType == nil {
+				continue
+			}
 
-				err := i.BaseType.validateValue(mapValue, depth+1)
-				if err != nil {
-					return err
-				}
+			if err := i.BaseType.validateValue(actual[key], depth+1); err != nil {
+				return err
 			}
 		}
 
@@ -177,6 +205,9 @@ func (i *Item) validateValue(v any, depth int) error {
 			}
 		}
 
+	case TypeUnion:
+		return i.validateUnion(v, depth)
+
 	case TypeUUID:
 		_, err := getUUIDValue(v)
 		if err != nil {
@@ -190,59 +221,34 @@ func (i *Item) validateValue(v any, depth int) error {
 		}
 
 	case TypeInt:
-		value, err := getIntValue(v)
+		// A value with a fractional part is not an integer, even when it
+		// is a valid JSON number.
+		value, err := getIntegerValue(v)
 		if err != nil {
 			return ErrInvalidData.Context(i.Name).Value(v)
 		}
 
-		if i.HasMinValue {
-			t, _ := getIntValue(i.MinValue)
-			if value < t {
-				return ErrValueOutOfRange.Context(i.Name).Value(value)
-			}
+		if err := i.validateInteger(value); err != nil {
+			return err
 		}
 
-		if i.HasMaxValue {
-			t, _ := getIntValue(i.MaxValue)
-			if value > t {
-				return ErrValueOutOfRange.Context(i.Name).Value(value)
+	case TypeFloat:
+		if i.Decimal {
+			value, err := getDecimalValue(v)
+			if err != nil {
+				return ErrInvalidData.Context(i.Name).Value(v)
 			}
-		}
 
-		found := false
-
-		for _, enum := range i.Enums {
-			enumValue, _ := getIntValue(enum)
-
-			if value == enumValue {
-				found = true
-
-				break
-			}
+			return i.validateDecimal(value, v)
 		}
 
-		if len(i.Enums) > 0 && !found {
-			return ErrInvalidEnumeratedValue.Context(i.Name).Value(value).Expected(i.Enums)
-		}
-
-	case TypeFloat:
 		value, err := getFloatValue(v)
 		if err != nil {
 			return ErrInvalidData.Context(i.Name).Value(v)
 		}
 
-		if i.HasMinValue {
-			t, _ := getFloatValue(i.MinValue)
-			if value < t {
-				return ErrValueOutOfRange.Context(i.Name).Value(value)
-			}
-		}
-
-		if i.HasMaxValue {
-			t, _ := getFloatValue(i.MaxValue)
-			if value > t {
-				return ErrValueOutOfRange.Context(i.Name).Value(value)
-			}
+		if err := i.validateFloat(value); err != nil {
+			return err
 		}
 
 	case TypeList:
@@ -316,6 +322,10 @@ func (i *Item) validateValue(v any, depth int) error {
 			}
 		}
 
+		if err := i.validatePattern(value); err != nil {
+			return err
+		}
+
 		found := false
 
 		for _, enum := range i.Enums {
@@ -349,46 +359,104 @@ func (i *Item) validateValue(v any, depth int) error {
 			m = a[0]
 		}
 
-		// Check if there are any field names that are not defined for the struct.
-		// We do not do this if the validation allows "foreign" key values
-		if !i.AllowForeignKey {
-			for key := range m {
-				found := false
-
-				for _, field := range i.Fields {
-					if field.Name == key {
-						found = true
-
-						break
-					}
-				}
-
-				if !found {
-					return ErrInvalidFieldName.Context(i.Name).Value(key)
-				}
-			}
+		// Find the key used for each field. This reports any field names that
+		// are not defined for the struct, unless the validation allows "foreign"
+		// key values, and any fields that more than one key matches.
+		keys, err := i.matchFields(m)
+		if err != nil {
+			return err
 		}
 
 		// Verify each field found in the map against the struct's fields.
 		for _, field := range i.Fields {
-			fieldValue, exists := m[field.Name]
-			if !exists {
-				if field.Required {
-					return ErrRequired.Value(field.Name)
-				}
+			key, exists := keys[field]
+			if !exists && field.Required {
+				return ErrRequired.Value(field.Name)
+			}
+
+			// Check the rules that decide if the field must be present, based
+			// on the other fields in the object.
+			if condition, failed := i.failedCondition(field, m, keys); failed {
+				return conditionError(field, condition)
+			}
 
+			if !exists {
 				continue
 			}
 
-			err := field.validateValue(fieldValue, depth+1)
+			err := field.validateValue(m[key], depth+1)
 			if err != nil {
 				return err
 			}
 		}
 
+		// Check the values of the keys that are not fields of the structure.
+		if err := i.validateProperties(m, keys, depth); err != nil {
+			return err
+		}
+
+		// Compare the fields that have rules referring to other fields, now
+		// that each field is known to be valid.
+		if err := i.validateFieldRules(m, keys); err != nil {
+			return err
+		}
+
+		if err := i.validateGroups(m, keys); err != nil {
+			return err
+		}
+
+		if err := i.validateExpressions(m, keys); err != nil {
+			return err
+		}
+
+		// If the structure type checks its own values, do that now that
+		// the fields are known to be valid.
+		if i.TypeName != "" {
+			return i.validateSelf(m)
+		}
+
 	default:
 		return ErrUnimplemented.Context(i.Name).Value(i.ItemType.String())
 	}
 
 	return nil
 }
+
+// quotedValue returns the value encoded inside a JSON string, for an item that
+// is quoted. Like encoding/json, the string must contain a single JSON number
+// or boolean for numeric and boolean items, or a single JSON string for any
+// other item.
+func (i *Item) quotedValue(v any) (any, error) {
+	text, ok := v.(string)
+	if !ok {
+		return nil, ErrInvalidData.Context(i.Name).Value(v)
+	}
+
+	var value any
+
+	if err := decodeJSON([]byte(text), &value); err != nil {
+		return nil, ErrInvalidData.Context(i.Name).Value(text)
+	}
+
+	// Find the type of the value inside any pointers.
+	base := i
+	for base != nil && base.ItemType == TypePointer {
+		base = base.BaseType
+	}
+
+	scalar := base != nil && (base.ItemType == TypeInt || base.ItemType == TypeFloat || base.ItemType == TypeBool)
+
+	switch value.(type) {
+	case string:
+		if !scalar {
+			return value, nil
+		}
+
+	case json.Number, bool:
+		if scalar {
+			return value, nil
+		}
+	}
+
+	return nil, ErrInvalidData.Context(i.Name).Value(text)
+}